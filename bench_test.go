@@ -0,0 +1,83 @@
+package espresso_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/googollee/module"
+
+	"github.com/googollee/go-espresso"
+)
+
+func BenchmarkServeHTTP(b *testing.B) {
+	type DB struct{}
+	dbModule := module.New[*DB]()
+
+	newServer := func(middlewares ...espresso.HandleFunc) *espresso.Espresso {
+		espo := espresso.New()
+		espo.AddModule(espresso.LogModule.ProvideValue(slog.New(slog.NewTextHandler(io.Discard, nil))))
+		espo.AddModule(dbModule.ProvideWithFunc(func(context.Context) (*DB, error) {
+			return &DB{}, nil
+		}))
+		espo.Use(middlewares...)
+		espo.HandleFunc(func(ctx espresso.Context) error {
+			var id int
+			if err := ctx.Endpoint(http.MethodGet, "/books/{id}").
+				BindPath("id", &id).
+				End(); err != nil {
+				return err
+			}
+
+			if dbModule.Value(ctx) == nil {
+				b.Fatal("no db")
+			}
+			return nil
+		})
+
+		if err := espo.Start(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+		return espo
+	}
+
+	tests := []struct {
+		name string
+		espo *espresso.Espresso
+	}{
+		{
+			name: "Modules",
+			espo: newServer(),
+		},
+		{
+			name: "PerRequest",
+			espo: newServer(espresso.ProvidePerRequest(dbModule, func(espresso.Context) (*DB, error) {
+				return &DB{}, nil
+			})),
+		},
+	}
+
+	for _, tc := range tests {
+		b.Run(tc.name, func(b *testing.B) {
+			req := httptest.NewRequest(http.MethodGet, "/books/1", nil)
+			w := &discardWriter{header: make(http.Header)}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				tc.espo.ServeHTTP(w, req)
+			}
+		})
+	}
+}
+
+type discardWriter struct {
+	header http.Header
+}
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w *discardWriter) WriteHeader(int)             {}
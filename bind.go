@@ -1,7 +1,9 @@
 package espresso
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"reflect"
 	"strings"
 )
@@ -14,6 +16,10 @@ const (
 	BindFormParam
 	BindQueryParam
 	BindHeadParam
+	BindFileParam
+	// BindBodyField is the source of fields of request bodies decoded by `RPC()` and `RPCConsume()`. It's only used in
+	// errors of validating bodies, not for binding.
+	BindBodyField
 )
 
 func (b BindSource) String() string {
@@ -26,6 +32,10 @@ func (b BindSource) String() string {
 		return "query"
 	case BindHeadParam:
 		return "head"
+	case BindFileParam:
+		return "file"
+	case BindBodyField:
+		return "body"
 	}
 	return fmt.Sprintf("unknown(%d)", int(b))
 }
@@ -34,15 +44,105 @@ func (b BindSource) Valid() bool {
 	return !strings.HasPrefix(b.String(), "unknown")
 }
 
+// SplitStyle describes how multiple values of a param are sent in a request.
+type SplitStyle int
+
+const (
+	// SplitRepeat sends values with repeated keys, like `?tag=a&tag=b`.
+	SplitRepeat SplitStyle = iota
+	// SplitComma sends values separated by commas, like `?ids=1,2,3`.
+	SplitComma
+	// SplitPipe sends values separated by pipes, like `?ids=1|2|3`.
+	SplitPipe
+)
+
+func (s SplitStyle) String() string {
+	switch s {
+	case SplitRepeat:
+		return "repeat"
+	case SplitComma:
+		return "comma"
+	case SplitPipe:
+		return "pipe"
+	}
+	return fmt.Sprintf("unknown(%d)", int(s))
+}
+
+func (s SplitStyle) split(values []string) []string {
+	var sep string
+	switch s {
+	case SplitComma:
+		sep = ","
+	case SplitPipe:
+		sep = "|"
+	default:
+		return values
+	}
+
+	var ret []string
+	for _, v := range values {
+		ret = append(ret, strings.Split(v, sep)...)
+	}
+	return ret
+}
+
 type BindFunc func(any, string) error
 
+// BindMultiFunc binds multiple values of a param to a slice.
+type BindMultiFunc func(any, []string) error
+
 type BindParam struct {
-	Key  string
-	From BindSource
-	Type reflect.Type
-	Func BindFunc
+	Key       string
+	From      BindSource
+	Type      reflect.Type
+	Func      BindFunc
+	MultiFunc BindMultiFunc
+	Split     SplitStyle
+	Required  bool
+	Default   *string
+	Rules     []Rule
+
+	// MaxSize and ContentTypes limit files bound by `BindFile()`.
+	MaxSize      int64
+	ContentTypes []string
+
+	// check checks bound values with `Rules`. It's built when registering handlers.
+	check func(v any) error
 }
 
+// Multi returns true if the param binds multiple values to a slice.
+func (p BindParam) Multi() bool {
+	return p.MultiFunc != nil || (p.From == BindFileParam && p.Type == fileHeadersType)
+}
+
+// BindOption configures a binding param.
+type BindOption func(*BindParam)
+
+// Split sets how multiple values of a param are sent. The default style is `SplitRepeat`.
+func Split(style SplitStyle) BindOption {
+	return func(p *BindParam) {
+		p.Split = style
+	}
+}
+
+// Required marks a param as required. Binding returns an error with `ErrMissingParam` if the param is absent.
+// Path params are always required.
+func Required() BindOption {
+	return func(p *BindParam) {
+		p.Required = true
+	}
+}
+
+// Default sets the value to bind if the param is absent.
+func Default(value string) BindOption {
+	return func(p *BindParam) {
+		p.Default = &value
+	}
+}
+
+// ErrMissingParam is the error when a required param is absent in a request.
+var ErrMissingParam = errors.New("missing required value")
+
 // BindError describes the error when binding a param.
 type BindError struct {
 	Key  string
@@ -68,6 +168,20 @@ func (b BindError) Unwrap() error {
 	return b.Err
 }
 
+// BindIndexError describes the error when binding an element of multiple values.
+type BindIndexError struct {
+	Index int
+	Err   error
+}
+
+func (e BindIndexError) Error() string {
+	return fmt.Sprintf("element %d: %v", e.Index, e.Err)
+}
+
+func (e BindIndexError) Unwrap() error {
+	return e.Err
+}
+
 // BindErrors describes all errors when binding params.
 type BindErrors []BindError
 
@@ -79,6 +193,28 @@ func (e BindErrors) Error() string {
 	return strings.Join(errStr, ", ")
 }
 
+// status returns the code of the first error with an HTTP code, like HTTP 413 for a too large body, or HTTP 400.
+func (e BindErrors) status() int {
+	for _, err := range e {
+		if coder, ok := err.Err.(HTTPError); ok {
+			return coder.HTTPCode()
+		}
+	}
+	return http.StatusBadRequest
+}
+
+func (e BindErrors) errorDetails() []ErrorDetail {
+	ret := make([]ErrorDetail, 0, len(e))
+	for _, err := range e {
+		ret = append(ret, ErrorDetail{
+			Field:   err.Key,
+			Source:  err.From.String(),
+			Message: err.Err.Error(),
+		})
+	}
+	return ret
+}
+
 func (e BindErrors) Unwrap() []error {
 	if len(e) == 0 {
 		return nil
@@ -93,20 +229,84 @@ func (e BindErrors) Unwrap() []error {
 	return ret
 }
 
-func newBindParam(key string, src BindSource, v any) (BindParam, error) {
-	vt, fn := getBindFunc(v)
-	if fn == nil {
-		return BindParam{}, fmt.Errorf("not support to bind %s key %q to %T", src, key, v)
-	}
-
-	if !src.Valid() {
+func newBindParam(key string, src BindSource, v any, opts ...BindOption) (BindParam, error) {
+	if !src.Valid() || src == BindBodyField {
 		return BindParam{}, fmt.Errorf("not support bind type %d", src)
 	}
 
-	return BindParam{
+	ret := BindParam{
 		Key:  key,
 		From: src,
-		Type: vt,
-		Func: fn,
-	}, nil
+	}
+
+	if src == BindFileParam {
+		var err error
+		if ret, err = newFileParam(ret, v); err != nil {
+			return BindParam{}, err
+		}
+	} else if vt, fn := getBindFunc(v); fn != nil {
+		ret.Type, ret.Func = vt, fn
+	} else if vt, fn := getBindMultiFunc(v); fn != nil {
+		ret.Type, ret.MultiFunc = vt, fn
+	} else {
+		return BindParam{}, fmt.Errorf("not support to bind %s key %q to %T", src, key, v)
+	}
+
+	for _, opt := range opts {
+		opt(&ret)
+	}
+
+	for _, rule := range ret.Rules {
+		if !rule.accepts(ret.Type) {
+			return BindParam{}, fmt.Errorf("rule %q doesn't support to bind %s key %q to %T", rule.Name, src, key, v)
+		}
+	}
+
+	check, err := newParamCheck(ret.Type, ret.Rules)
+	if err != nil {
+		return BindParam{}, fmt.Errorf("bind %s key %q to %T: %w", src, key, v, err)
+	}
+	ret.check = check
+
+	return ret, nil
+}
+
+// requirePathParam makes the path param `key` of the pattern `path` required, so a missing value fails with
+// `ErrMissingParam`. A trailing wildcard like `{name...}` matches an empty value, so it's optional unless set by
+// `Required()`.
+func requirePathParam(path, key string) BindOption {
+	return func(p *BindParam) {
+		if !strings.Contains(path, "{"+key+"...}") {
+			p.Required = true
+		}
+	}
+}
+
+// bindValues binds `values` of a param to `v`, with the default value, the required flag and rules of the param.
+func bindValues(binder BindParam, values []string, v any) error {
+	if len(values) == 0 {
+		switch {
+		case binder.Default != nil:
+			values = []string{*binder.Default}
+		case binder.Required:
+			return ErrMissingParam
+		default:
+			return nil
+		}
+	}
+
+	var err error
+	if binder.Multi() {
+		err = binder.MultiFunc(v, binder.Split.split(values))
+	} else {
+		err = binder.Func(v, values[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	if binder.check == nil {
+		return nil
+	}
+	return binder.check(v)
 }
@@ -0,0 +1,365 @@
+package espresso_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/googollee/go-espresso"
+)
+
+func TestBindSources(t *testing.T) {
+	espo := espresso.New()
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		var id int
+		var limit uint16
+		var name string
+		var tenant string
+		if err := ctx.Endpoint(http.MethodPost, "/item/{id}").
+			BindPath("id", &id).
+			BindQuery("limit", &limit).
+			BindForm("name", &name).
+			BindHead("X-Tenant", &tenant).
+			End(); err != nil {
+			return espresso.Error(http.StatusBadRequest, err)
+		}
+
+		fmt.Fprintf(ctx.ResponseWriter(), "id=%d limit=%d name=%s tenant=%s", id, limit, name, tenant)
+		return nil
+	})
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		form     url.Values
+		tenant   string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "OK",
+			path:     "/item/1?limit=20",
+			form:     url.Values{"name": {"espresso"}},
+			tenant:   "tenant",
+			wantCode: http.StatusOK,
+			wantBody: "id=1 limit=20 name=espresso tenant=tenant",
+		},
+		{
+			name:     "InvalidQuery",
+			path:     "/item/1?limit=-1",
+			form:     url.Values{"name": {"espresso"}},
+			tenant:   "tenant",
+			wantCode: http.StatusBadRequest,
+			wantBody: `bind query with name "limit" to type uint16 error: strconv.ParseUint: parsing "-1": invalid syntax`,
+		},
+		{
+			name:     "InvalidPathAndQuery",
+			path:     "/item/abc?limit=abc",
+			wantCode: http.StatusBadRequest,
+			wantBody: `bind path with name "id" to type int error: strconv.ParseInt: parsing "abc": invalid syntax, bind query with name "limit" to type uint16 error: strconv.ParseUint: parsing "abc": invalid syntax`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, svr.URL+tc.path, strings.NewReader(tc.form.Encode()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("X-Tenant", tc.tenant)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if got, want := resp.StatusCode, tc.wantCode; got != want {
+				t.Errorf("resp.StatusCode = %d, want: %d", got, want)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := string(body), tc.wantBody; got != want {
+				t.Errorf("resp.Body = %q, want: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestBindPathWildcard(t *testing.T) {
+	type File struct {
+		Dir string `path:"dir"`
+	}
+
+	espo := espresso.New()
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		var path string
+		if err := ctx.Endpoint(http.MethodGet, "/files/{path...}").
+			BindPath("path", &path).
+			End(); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(ctx.ResponseWriter(), "path=%q", path)
+		return nil
+	})
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		var file File
+		if err := ctx.Endpoint(http.MethodGet, "/dirs/{dir...}").
+			BindStruct(&file).
+			End(); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(ctx.ResponseWriter(), "dir=%q", file.Dir)
+		return nil
+	})
+
+	tests := []struct {
+		path     string
+		wantBody string
+	}{
+		{"/files/a/b.txt", `path="a/b.txt"`},
+		{"/files/", `path=""`},
+		{"/dirs/", `dir=""`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			espo.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			if got, want := resp.Code, http.StatusOK; got != want {
+				t.Errorf("resp.Code = %d, want: %d, body: %q", got, want, resp.Body.String())
+			}
+			if got, want := resp.Body.String(), tc.wantBody; got != want {
+				t.Errorf("resp.Body = %q, want: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestBindStruct(t *testing.T) {
+	type Page struct {
+		Limit  int `query:"limit"`
+		Offset int `query:"offset"`
+	}
+	type Params struct {
+		Page
+		ID     int64  `path:"id"`
+		Name   string `form:"name"`
+		Tenant string `header:"X-Tenant"`
+		Ignore string
+	}
+
+	espo := espresso.New()
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		var params Params
+		if err := ctx.Endpoint(http.MethodPost, "/item/{id}").
+			BindStruct(&params).
+			End(); err != nil {
+			return espresso.Error(http.StatusBadRequest, err)
+		}
+
+		fmt.Fprintf(ctx.ResponseWriter(), "%+v", params)
+		return nil
+	})
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	form := url.Values{"name": {"espresso"}, "Ignore": {"value"}}
+	req, err := http.NewRequest(http.MethodPost, svr.URL+"/item/1?limit=20&offset=40", strings.NewReader(form.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Tenant", "tenant")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := resp.StatusCode, http.StatusOK; got != want {
+		t.Fatalf("resp.StatusCode = %d, want: %d, body: %s", got, want, body)
+	}
+
+	if got, want := string(body), "{Page:{Limit:20 Offset:40} ID:1 Name:espresso Tenant:tenant Ignore:}"; got != want {
+		t.Errorf("resp.Body = %q, want: %q", got, want)
+	}
+}
+
+func TestBindMultiValues(t *testing.T) {
+	type Filter struct {
+		Owners  []string `query:"owner"`
+		Shelves []uint8  `query:"shelves,pipe"`
+	}
+
+	espo := espresso.New()
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		var tags []string
+		var ids []int64
+		var filter Filter
+		if err := ctx.Endpoint(http.MethodGet, "/items").
+			BindQuery("tag", &tags).
+			BindQuery("ids", &ids, espresso.Split(espresso.SplitComma)).
+			BindStruct(&filter).
+			End(); err != nil {
+			return espresso.Error(http.StatusBadRequest, err)
+		}
+
+		fmt.Fprintf(ctx.ResponseWriter(), "tags=%q ids=%v filter=%+v", tags, ids, filter)
+		return nil
+	})
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "OK",
+			query:    "tag=a&tag=b&ids=1,2,3&owner=me&shelves=1|2",
+			wantCode: http.StatusOK,
+			wantBody: `tags=["a" "b"] ids=[1 2 3] filter={Owners:[me] Shelves:[1 2]}`,
+		},
+		{
+			name:     "Empty",
+			query:    "",
+			wantCode: http.StatusOK,
+			wantBody: `tags=[] ids=[] filter={Owners:[] Shelves:[]}`,
+		},
+		{
+			name:     "InvalidElement",
+			query:    "ids=1,a,3",
+			wantCode: http.StatusBadRequest,
+			wantBody: `bind query with name "ids" to type []int64 error: element 1: strconv.ParseInt: parsing "a": invalid syntax`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Get(svr.URL + "/items?" + tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if got, want := resp.StatusCode, tc.wantCode; got != want {
+				t.Errorf("resp.StatusCode = %d, want: %d", got, want)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := string(body), tc.wantBody; got != want {
+				t.Errorf("resp.Body = %q, want: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestBindOptions(t *testing.T) {
+	type Page struct {
+		Limit  int    `query:"limit,default=20"`
+		Cursor string `query:"cursor,required"`
+	}
+
+	espo := espresso.New()
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		var id int
+		var sort string
+		var offset *int
+		var page Page
+		if err := ctx.Endpoint(http.MethodGet, "/items/{id}").
+			BindPath("id", &id).
+			BindQuery("sort", &sort, espresso.Required()).
+			BindQuery("offset", &offset).
+			BindStruct(&page).
+			End(); err != nil {
+			for _, e := range err {
+				if errors.Is(e, espresso.ErrMissingParam) {
+					fmt.Fprintf(ctx.ResponseWriter(), "missing %s;", e.Key)
+				}
+			}
+			return nil
+		}
+
+		if offset == nil {
+			fmt.Fprintf(ctx.ResponseWriter(), "id=%d sort=%s offset=nil page=%+v", id, sort, page)
+		} else {
+			fmt.Fprintf(ctx.ResponseWriter(), "id=%d sort=%s offset=%d page=%+v", id, sort, *offset, page)
+		}
+		return nil
+	})
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	tests := []struct {
+		name     string
+		query    string
+		wantBody string
+	}{
+		{
+			name:     "Defaults",
+			query:    "sort=name&cursor=c",
+			wantBody: "id=1 sort=name offset=nil page={Limit:20 Cursor:c}",
+		},
+		{
+			name:     "All",
+			query:    "sort=name&cursor=c&offset=10&limit=5",
+			wantBody: "id=1 sort=name offset=10 page={Limit:5 Cursor:c}",
+		},
+		{
+			name:     "Missing",
+			query:    "offset=10",
+			wantBody: "missing sort;missing cursor;",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Get(svr.URL + "/items/1?" + tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := string(body), tc.wantBody; got != want {
+				t.Errorf("resp.Body = %q, want: %q", got, want)
+			}
+		})
+	}
+}
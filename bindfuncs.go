@@ -1,12 +1,48 @@
 package espresso
 
 import (
+	"encoding"
 	"reflect"
 	"strconv"
+	"sync"
+	"time"
+	"unsafe"
 )
 
+var bindFuncs sync.Map // reflect.Type -> BindFunc
+
+// RegisterBindFunc registers `fn` to bind a string to the type `T`.
+// Registered functions take precedence over builtin ones, so it could also change how builtin types are parsed.
+// It should be called before registering handlers, usually in `init()`.
+func RegisterBindFunc[T any](fn func(string) (T, error)) {
+	var t T
+	bindFuncs.Store(reflect.TypeOf(&t).Elem(), BindFunc(func(v any, param string) error {
+		ret, err := fn(param)
+		if err != nil {
+			return err
+		}
+		p := v.(*T)
+		*p = ret
+		return nil
+	}))
+}
+
 func getBindFunc(v any) (reflect.Type, BindFunc) {
+	pt := reflect.TypeOf(v)
+	if pt == nil || pt.Kind() != reflect.Pointer {
+		return nil, nil
+	}
+	t := pt.Elem()
+
+	if fn, ok := bindFuncs.Load(t); ok {
+		return t, fn.(BindFunc)
+	}
+
 	switch v.(type) {
+	case *bool:
+		return bindBool()
+	case *time.Duration:
+		return bindDuration()
 	case *string:
 		return bindString[string]()
 	case *int:
@@ -35,7 +71,272 @@ func getBindFunc(v any) (reflect.Type, BindFunc) {
 		return bindFloat[float64](64)
 	}
 
-	return nil, nil
+	if _, ok := v.(encoding.TextUnmarshaler); ok {
+		return t, bindTextUnmarshaler
+	}
+
+	if t.Kind() == reflect.Pointer {
+		return bindPointer(t)
+	}
+
+	return bindUnderlying(t)
+}
+
+// bindPointer binds to a pointer, like `**int`. It allocates a new value only when binding, so the pointer stays nil
+// if the param is absent.
+func bindPointer(t reflect.Type) (reflect.Type, BindFunc) {
+	_, fn := getBindFunc(reflect.New(t.Elem()).Interface())
+	if fn == nil {
+		return nil, nil
+	}
+
+	if builtin, ok := builtinTypes[t.Elem()]; ok {
+		return t, builtin.bindPointer(fn)
+	}
+
+	return t, func(v any, param string) error {
+		p := reflect.New(t.Elem())
+		if err := fn(p.Interface(), param); err != nil {
+			return err
+		}
+		reflect.ValueOf(v).Elem().Set(p)
+		return nil
+	}
+}
+
+func getBindMultiFunc(v any) (reflect.Type, BindMultiFunc) {
+	pt := reflect.TypeOf(v)
+	if pt == nil || pt.Kind() != reflect.Pointer || pt.Elem().Kind() != reflect.Slice {
+		return nil, nil
+	}
+	st := pt.Elem()
+
+	et := st.Elem()
+	_, fn := getBindFunc(reflect.New(et).Interface())
+	if fn == nil {
+		return nil, nil
+	}
+
+	if builtin, ok := builtinTypes[et]; ok {
+		return st, builtin.bindSlice(fn)
+	}
+
+	return st, func(v any, params []string) error {
+		slice := reflect.MakeSlice(st, len(params), len(params))
+		for i, param := range params {
+			if err := fn(slice.Index(i).Addr().Interface(), param); err != nil {
+				return BindIndexError{Index: i, Err: err}
+			}
+		}
+		reflect.ValueOf(v).Elem().Set(slice)
+		return nil
+	}
+}
+
+// builtinType has functions built with the type parameter of a builtin type, to bind values of the type without
+// reflecting.
+type builtinType struct {
+	// pointer, slicePointer and pointerPointer convert a raw pointer to `*T`, `*[]T` and `**T`.
+	pointer        func(p unsafe.Pointer) any
+	slicePointer   func(p unsafe.Pointer) any
+	pointerPointer func(p unsafe.Pointer) any
+
+	bindPointer func(fn BindFunc) BindFunc
+	bindSlice   func(fn BindFunc) BindMultiFunc
+
+	// rawPointer converts `*T` or `*[]T` in an interface to a raw pointer.
+	rawPointer func(v any) unsafe.Pointer
+	// value, isZero and sliceElems read `T` or `[]T` at a raw pointer, to check rules.
+	value      func(p unsafe.Pointer) any
+	isZero     func(p unsafe.Pointer) bool
+	sliceElems func(p unsafe.Pointer) (unsafe.Pointer, int)
+}
+
+func newBuiltinType[T comparable]() builtinType {
+	return builtinType{
+		pointer: func(p unsafe.Pointer) any {
+			return (*T)(p)
+		},
+		slicePointer: func(p unsafe.Pointer) any {
+			return (*[]T)(p)
+		},
+		pointerPointer: func(p unsafe.Pointer) any {
+			return (**T)(p)
+		},
+		bindPointer: func(fn BindFunc) BindFunc {
+			return func(v any, param string) error {
+				p := new(T)
+				if err := fn(p, param); err != nil {
+					return err
+				}
+				*v.(**T) = p
+				return nil
+			}
+		},
+		bindSlice: func(fn BindFunc) BindMultiFunc {
+			return func(v any, params []string) error {
+				slice := make([]T, len(params))
+				for i, param := range params {
+					if err := fn(&slice[i], param); err != nil {
+						return BindIndexError{Index: i, Err: err}
+					}
+				}
+				*v.(*[]T) = slice
+				return nil
+			}
+		},
+		rawPointer: func(v any) unsafe.Pointer {
+			if p, ok := v.(*[]T); ok {
+				return unsafe.Pointer(p)
+			}
+			return unsafe.Pointer(v.(*T))
+		},
+		value: func(p unsafe.Pointer) any {
+			return *(*T)(p)
+		},
+		isZero: func(p unsafe.Pointer) bool {
+			var zero T
+			return *(*T)(p) == zero
+		},
+		sliceElems: func(p unsafe.Pointer) (unsafe.Pointer, int) {
+			slice := *(*[]T)(p)
+			return unsafe.Pointer(unsafe.SliceData(slice)), len(slice)
+		},
+	}
+}
+
+// builtinTypes are types bound without reflecting. Other types are bound with reflection.
+var builtinTypes = map[reflect.Type]builtinType{
+	reflect.TypeOf(false):            newBuiltinType[bool](),
+	reflect.TypeOf(""):               newBuiltinType[string](),
+	reflect.TypeOf(int(0)):           newBuiltinType[int](),
+	reflect.TypeOf(int8(0)):          newBuiltinType[int8](),
+	reflect.TypeOf(int16(0)):         newBuiltinType[int16](),
+	reflect.TypeOf(int32(0)):         newBuiltinType[int32](),
+	reflect.TypeOf(int64(0)):         newBuiltinType[int64](),
+	reflect.TypeOf(uint(0)):          newBuiltinType[uint](),
+	reflect.TypeOf(uint8(0)):         newBuiltinType[uint8](),
+	reflect.TypeOf(uint16(0)):        newBuiltinType[uint16](),
+	reflect.TypeOf(uint32(0)):        newBuiltinType[uint32](),
+	reflect.TypeOf(uint64(0)):        newBuiltinType[uint64](),
+	reflect.TypeOf(float32(0)):       newBuiltinType[float32](),
+	reflect.TypeOf(float64(0)):       newBuiltinType[float64](),
+	reflect.TypeOf(time.Duration(0)): newBuiltinType[time.Duration](),
+	reflect.TypeOf(time.Time{}):      newBuiltinType[time.Time](),
+}
+
+// builtinPointer returns the function converting a raw pointer to a pointer of `t`, if `t` is a builtin type, or a
+// slice or a pointer of a builtin type.
+func builtinPointer(t reflect.Type) (func(p unsafe.Pointer) any, bool) {
+	if builtin, ok := builtinTypes[t]; ok {
+		return builtin.pointer, true
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		if builtin, ok := builtinTypes[t.Elem()]; ok && t == reflect.SliceOf(t.Elem()) {
+			return builtin.slicePointer, true
+		}
+	case reflect.Pointer:
+		if builtin, ok := builtinTypes[t.Elem()]; ok && t == reflect.PointerTo(t.Elem()) {
+			return builtin.pointerPointer, true
+		}
+	}
+
+	return nil, false
+}
+
+// rawPointer returns the function converting a pointer of `t` in an interface to a raw pointer.
+func rawPointer(t reflect.Type) func(v any) unsafe.Pointer {
+	if builtin, ok := builtinTypes[t]; ok {
+		return builtin.rawPointer
+	}
+	if t.Kind() == reflect.Slice {
+		if builtin, ok := builtinTypes[t.Elem()]; ok && t == reflect.SliceOf(t.Elem()) {
+			return builtin.rawPointer
+		}
+	}
+
+	return func(v any) unsafe.Pointer {
+		return reflect.ValueOf(v).UnsafePointer()
+	}
+}
+
+// sliceElems returns the function getting the pointer to the first element and the length of a slice of type `t` at
+// a raw pointer.
+func sliceElems(t reflect.Type) func(p unsafe.Pointer) (unsafe.Pointer, int) {
+	if builtin, ok := builtinTypes[t.Elem()]; ok {
+		return builtin.sliceElems
+	}
+
+	return func(p unsafe.Pointer) (unsafe.Pointer, int) {
+		v := reflect.NewAt(t, p).Elem()
+		return v.UnsafePointer(), v.Len()
+	}
+}
+
+// underlyingPointers converts a raw pointer to a pointer of the underlying type, by kinds.
+var underlyingPointers = map[reflect.Kind]func(unsafe.Pointer) any{
+	reflect.Bool:    func(p unsafe.Pointer) any { return (*bool)(p) },
+	reflect.String:  func(p unsafe.Pointer) any { return (*string)(p) },
+	reflect.Int:     func(p unsafe.Pointer) any { return (*int)(p) },
+	reflect.Int8:    func(p unsafe.Pointer) any { return (*int8)(p) },
+	reflect.Int16:   func(p unsafe.Pointer) any { return (*int16)(p) },
+	reflect.Int32:   func(p unsafe.Pointer) any { return (*int32)(p) },
+	reflect.Int64:   func(p unsafe.Pointer) any { return (*int64)(p) },
+	reflect.Uint:    func(p unsafe.Pointer) any { return (*uint)(p) },
+	reflect.Uint8:   func(p unsafe.Pointer) any { return (*uint8)(p) },
+	reflect.Uint16:  func(p unsafe.Pointer) any { return (*uint16)(p) },
+	reflect.Uint32:  func(p unsafe.Pointer) any { return (*uint32)(p) },
+	reflect.Uint64:  func(p unsafe.Pointer) any { return (*uint64)(p) },
+	reflect.Float32: func(p unsafe.Pointer) any { return (*float32)(p) },
+	reflect.Float64: func(p unsafe.Pointer) any { return (*float64)(p) },
+}
+
+// bindUnderlying binds named types, like `type UserID int64`, with the bind function of its underlying type.
+// The pointer is converted to the underlying type directly, without reflecting on values.
+func bindUnderlying(t reflect.Type) (reflect.Type, BindFunc) {
+	toUnderlying, ok := underlyingPointers[t.Kind()]
+	if !ok {
+		return nil, nil
+	}
+
+	ut, fn := getBindFunc(toUnderlying(nil))
+	if fn == nil || ut == t {
+		return nil, nil
+	}
+
+	return t, func(v any, param string) error {
+		return fn(toUnderlying(reflect.ValueOf(v).UnsafePointer()), param)
+	}
+}
+
+func bindTextUnmarshaler(v any, param string) error {
+	return v.(encoding.TextUnmarshaler).UnmarshalText([]byte(param))
+}
+
+func bindBool() (reflect.Type, BindFunc) {
+	return reflect.TypeOf(false), func(v any, param string) error {
+		b, err := strconv.ParseBool(param)
+		if err != nil {
+			return err
+		}
+		p := v.(*bool)
+		*p = b
+		return nil
+	}
+}
+
+func bindDuration() (reflect.Type, BindFunc) {
+	return reflect.TypeOf(time.Duration(0)), func(v any, param string) error {
+		d, err := time.ParseDuration(param)
+		if err != nil {
+			return err
+		}
+		p := v.(*time.Duration)
+		*p = d
+		return nil
+	}
 }
 
 type integer interface {
@@ -0,0 +1,193 @@
+package espresso
+
+import (
+	"fmt"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+type testUserID int64
+
+type testTenant string
+
+type testPoint struct {
+	X, Y int
+}
+
+func init() {
+	RegisterBindFunc(func(param string) (testPoint, error) {
+		var ret testPoint
+		if _, err := fmt.Sscanf(param, "%d,%d", &ret.X, &ret.Y); err != nil {
+			return ret, err
+		}
+		return ret, nil
+	})
+}
+
+func TestBindFuncs(t *testing.T) {
+	tests := []struct {
+		name  string
+		v     any
+		param string
+		want  any
+	}{
+		{"Bool", new(bool), "true", true},
+		{"Duration", new(time.Duration), "1m30s", 90 * time.Second},
+		{"Time", new(time.Time), "2024-01-02T03:04:05Z", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"TextUnmarshaler", new(netip.Addr), "127.0.0.1", netip.MustParseAddr("127.0.0.1")},
+		{"NamedInt", new(testUserID), "42", testUserID(42)},
+		{"NamedString", new(testTenant), "tenant", testTenant("tenant")},
+		{"Registered", new(testPoint), "1,2", testPoint{X: 1, Y: 2}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			typ, fn := getBindFunc(tc.v)
+			if fn == nil {
+				t.Fatalf("getBindFunc(%T) returns nil", tc.v)
+			}
+
+			if got, want := typ, reflect.TypeOf(tc.want); got != want {
+				t.Errorf("getBindFunc(%T) type = %v, want: %v", tc.v, got, want)
+			}
+
+			if err := fn(tc.v, tc.param); err != nil {
+				t.Fatalf("bind %q error: %v", tc.param, err)
+			}
+
+			if got, want := reflect.ValueOf(tc.v).Elem().Interface(), tc.want; got != want {
+				t.Errorf("bind %q = %v, want: %v", tc.param, got, want)
+			}
+		})
+	}
+}
+
+func TestBindFuncsError(t *testing.T) {
+	var id testUserID
+	_, fn := getBindFunc(&id)
+	if err := fn(&id, "abc"); err == nil || !strings.Contains(err.Error(), "invalid syntax") {
+		t.Errorf("bind \"abc\" to testUserID error = %v, want: invalid syntax", err)
+	}
+
+	if _, fn := getBindFunc(&struct{}{}); fn != nil {
+		t.Errorf("getBindFunc(*struct{}) = %v, want: nil", fn)
+	}
+}
+
+func TestBindFuncsAllocs(t *testing.T) {
+	var id testUserID
+	_, fn := getBindFunc(&id)
+	v := any(&id)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := fn(v, "42"); err != nil {
+			t.Fatalf("bind \"42\" error: %v", err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("bind to testUserID allocs = %v, want: 0", allocs)
+	}
+}
+
+func TestValidateAllocs(t *testing.T) {
+	var sort string
+	param, err := newBindParam("sort", BindQueryParam, &sort, Enum("asc", "desc"), Pattern("^[a-z]+$"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, values := any(&sort), []string{"asc"}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := bindValues(param, values, v); err != nil {
+			t.Fatalf("bind \"asc\" error: %v", err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("bind and validate sort allocs = %v, want: 0", allocs)
+	}
+
+	type book struct {
+		Title string   `validate:"required,maxlen=10"`
+		Pages *int     `validate:"min=1"`
+		Tags  []string `validate:"enum=go|web"`
+	}
+	validator, err := newValidator(reflect.TypeOf(book{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages := 10
+	b := book{Title: "espresso", Pages: &pages, Tags: []string{"go", "web"}}
+
+	allocs = testing.AllocsPerRun(100, func() {
+		if err := validator.validate(unsafe.Pointer(&b)); err != nil {
+			t.Fatalf("validate %+v error: %v", b, err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("validate book allocs = %v, want: 0", allocs)
+	}
+}
+
+func TestBindMultiFuncs(t *testing.T) {
+	tests := []struct {
+		name   string
+		v      any
+		params []string
+		want   any
+	}{
+		{"Builtin", new([]int), []string{"1", "2"}, []int{1, 2}},
+		{"Named", new([]testUserID), []string{"1", "2"}, []testUserID{1, 2}},
+		{"Registered", new([]testPoint), []string{"1,2"}, []testPoint{{X: 1, Y: 2}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, fn := getBindMultiFunc(tc.v)
+			if fn == nil {
+				t.Fatalf("getBindMultiFunc(%T) returns nil", tc.v)
+			}
+
+			if err := fn(tc.v, tc.params); err != nil {
+				t.Fatalf("bind %q error: %v", tc.params, err)
+			}
+
+			if got, want := reflect.ValueOf(tc.v).Elem().Interface(), tc.want; !reflect.DeepEqual(got, want) {
+				t.Errorf("bind %q = %v, want: %v", tc.params, got, want)
+			}
+		})
+	}
+}
+
+func TestBindPointerFuncs(t *testing.T) {
+	tests := []struct {
+		name  string
+		v     any
+		param string
+		want  any
+	}{
+		{"Builtin", new(*int), "42", 42},
+		{"Named", new(*testUserID), "42", testUserID(42)},
+		{"Registered", new(*testPoint), "1,2", testPoint{X: 1, Y: 2}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, fn := getBindFunc(tc.v)
+			if fn == nil {
+				t.Fatalf("getBindFunc(%T) returns nil", tc.v)
+			}
+
+			if err := fn(tc.v, tc.param); err != nil {
+				t.Fatalf("bind %q error: %v", tc.param, err)
+			}
+
+			if got, want := reflect.ValueOf(tc.v).Elem().Elem().Interface(), tc.want; got != want {
+				t.Errorf("bind %q = %v, want: %v", tc.param, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package espresso
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+var structTags = []struct {
+	tag string
+	src BindSource
+}{
+	{"path", BindPathParam},
+	{"query", BindQueryParam},
+	{"header", BindHeadParam},
+	{"form", BindFormParam},
+	{"file", BindFileParam},
+}
+
+// structField is a field of a struct to bind. The field is located by the offset from the beginning of the struct,
+// and functions to bind it are built when registering. Fields of builtin types, and slices or pointers of them, are
+// bound without reflecting when handling requests.
+type structField struct {
+	param BindParam
+	// pointer returns the typed pointer of the field in the struct at `base`.
+	pointer func(base unsafe.Pointer) any
+	// set binds `values` to the field in the struct at `base`.
+	set func(base unsafe.Pointer, values []string) error
+}
+
+func newStructField(param BindParam, t reflect.Type, offset uintptr) structField {
+	pointer := func(base unsafe.Pointer) any {
+		return reflect.NewAt(t, unsafe.Add(base, offset)).Interface()
+	}
+	if toPointer, ok := builtinPointer(t); ok {
+		pointer = func(base unsafe.Pointer) any {
+			return toPointer(unsafe.Add(base, offset))
+		}
+	}
+
+	return structField{
+		param:   param,
+		pointer: pointer,
+		set: func(base unsafe.Pointer, values []string) error {
+			return bindValues(param, values, pointer(base))
+		},
+	}
+}
+
+// newStructFields returns fields of the struct pointed by `v` to bind. `path` is the route pattern of the endpoint, to
+// check path params.
+func newStructFields(v any, path string) ([]structField, error) {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("bind struct: need a pointer to a struct, got %T", v)
+	}
+
+	return appendStructFields(nil, t.Elem(), 0, path)
+}
+
+func appendStructFields(fields []structField, t reflect.Type, offset uintptr, path string) ([]structField, error) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			var err error
+			fields, err = appendStructFields(fields, f.Type, offset+f.Offset, path)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		for _, tag := range structTags {
+			value, ok := f.Tag.Lookup(tag.tag)
+			if !ok || value == "" || value == "-" {
+				continue
+			}
+
+			key, opts, err := parseStructTag(value)
+			if err != nil {
+				return nil, fmt.Errorf("bind struct field %s.%s: %w", t, f.Name, err)
+			}
+
+			if tag, ok := f.Tag.Lookup("validate"); ok {
+				rules, err := parseRules(tag)
+				if err != nil {
+					return nil, fmt.Errorf("bind struct field %s.%s: %w", t, f.Name, err)
+				}
+				for _, rule := range rules {
+					opts = append(opts, withRule(rule))
+				}
+			}
+
+			if tag.src == BindPathParam {
+				opts = append(opts, requirePathParam(path, key))
+			}
+
+			param, err := newBindParam(key, tag.src, reflect.New(f.Type).Interface(), opts...)
+			if err != nil {
+				return nil, fmt.Errorf("bind struct field %s.%s: %w", t, f.Name, err)
+			}
+
+			fields = append(fields, newStructField(param, f.Type, offset+f.Offset))
+			break
+		}
+	}
+
+	return fields, nil
+}
+
+// parseStructTag parses a tag like `query:"ids,comma,required"` or `query:"limit,default=20"` to the key and options.
+func parseStructTag(tag string) (string, []BindOption, error) {
+	key, rest, _ := strings.Cut(tag, ",")
+
+	var opts []BindOption
+	for rest != "" {
+		var opt string
+		opt, rest, _ = strings.Cut(rest, ",")
+
+		switch opt {
+		case "repeat":
+			opts = append(opts, Split(SplitRepeat))
+		case "comma":
+			opts = append(opts, Split(SplitComma))
+		case "pipe":
+			opts = append(opts, Split(SplitPipe))
+		case "required":
+			opts = append(opts, Required())
+		default:
+			name, value, _ := strings.Cut(opt, "=")
+			switch name {
+			case "default":
+				opts = append(opts, Default(value))
+			case "maxsize":
+				n, err := strconv.ParseInt(value, 10, 64)
+				if err != nil {
+					return "", nil, fmt.Errorf("invalid tag option %q: %w", opt, err)
+				}
+				opts = append(opts, MaxSize(n))
+			case "types":
+				opts = append(opts, ContentTypes(strings.Split(value, "|")...))
+			default:
+				return "", nil, fmt.Errorf("unknown tag option %q", opt)
+			}
+		}
+	}
+
+	return key, opts, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"reflect"
 )
 
 var errBuilderEnd = errors.New("build end.")
@@ -13,13 +14,51 @@ type buildtimeEndpoint struct {
 	endpoint *Endpoint
 }
 
-func (b *buildtimeEndpoint) BindPath(key string, v any) EndpointBuilder {
-	bind, err := newBindParam(key, BindPathParam, v)
+func (b *buildtimeEndpoint) BindPath(key string, v any, opts ...BindOption) EndpointBuilder {
+	return b.bind(key, BindPathParam, v, opts...)
+}
+
+func (b *buildtimeEndpoint) BindQuery(key string, v any, opts ...BindOption) EndpointBuilder {
+	return b.bind(key, BindQueryParam, v, opts...)
+}
+
+func (b *buildtimeEndpoint) BindForm(key string, v any, opts ...BindOption) EndpointBuilder {
+	return b.bind(key, BindFormParam, v, opts...)
+}
+
+func (b *buildtimeEndpoint) BindHead(key string, v any, opts ...BindOption) EndpointBuilder {
+	return b.bind(key, BindHeadParam, v, opts...)
+}
+
+func (b *buildtimeEndpoint) BindFile(key string, v any, opts ...BindOption) EndpointBuilder {
+	return b.bind(key, BindFileParam, v, opts...)
+}
+
+func (b *buildtimeEndpoint) BindStruct(v any) EndpointBuilder {
+	fields, err := newStructFields(v, b.endpoint.Path)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	for _, field := range fields {
+		b.endpoint.params(field.param.From)[field.param.Key] = field.param
+	}
+	b.endpoint.structs[reflect.TypeOf(v)] = fields
+
+	return b
+}
+
+func (b *buildtimeEndpoint) bind(key string, src BindSource, v any, opts ...BindOption) EndpointBuilder {
+	if src == BindPathParam {
+		opts = append(opts, requirePathParam(b.endpoint.Path, key))
+	}
+
+	bind, err := newBindParam(key, src, v, opts...)
 	if err != nil {
 		panic(errorBind(bind, err).Error())
 	}
 
-	b.endpoint.PathParams[key] = bind
+	b.endpoint.params(src)[key] = bind
 
 	return b
 }
@@ -62,6 +101,10 @@ func (c *buildtimeContext) Error() error {
 	panic(errRegisterContextCall)
 }
 
+func (c *buildtimeContext) Multipart() (*MultipartReader, error) {
+	panic(errRegisterContextCall)
+}
+
 func (c *buildtimeContext) Next() {
 	panic(errRegisterContextCall)
 }
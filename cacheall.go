@@ -1,17 +1,26 @@
 package espresso
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"runtime/debug"
 )
 
 func cacheAllError(ctx Context) error {
-	wr := &responseWriter{
-		ResponseWriter: ctx.ResponseWriter(),
+	// Reuse the writer of `logHandling`, to let it log the error.
+	wr, ok := ctx.ResponseWriter().(*responseWriter)
+	if !ok {
+		wr = &responseWriter{
+			ResponseWriter: ctx.ResponseWriter(),
+		}
+		ctx = ctx.WithResponseWriter(wr)
 	}
+
 	code := http.StatusInternalServerError
 	defer func() {
 		err := checkError(ctx, recover())
+		wr.err = err
 
 		if wr.hasWritten || err == nil {
 			return
@@ -20,18 +29,20 @@ func cacheAllError(ctx Context) error {
 		if httpCoder, ok := err.(HTTPError); ok {
 			code = httpCoder.HTTPCode()
 		}
-		wr.WriteHeader(code)
 
-		codec := CodecsModule.Value(ctx)
-		if codec == nil {
-			fmt.Fprintf(wr, "%v", err)
+		codecs := CodecsModule.Value(ctx)
+		if codecs == nil {
+			wr.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			wr.WriteHeader(code)
+			fmt.Fprint(wr, responseMessage(err))
 			return
 		}
 
-		_ = codec.EncodeResponse(ctx, err)
+		// Respond errors with the fallback codec if no codec is acceptable, rather than another HTTP 406.
+		varyAccept(wr.Header())
+		_ = encodeResponse(ctx, codecs.Response(ctx), code, withRequestID(err, RequestIDOf(ctx)))
 	}()
 
-	ctx = ctx.WithResponseWriter(wr)
 	ctx.Next()
 
 	return nil
@@ -39,7 +50,7 @@ func cacheAllError(ctx Context) error {
 
 func checkError(ctx Context, perr any) error {
 	if perr != nil {
-		return Error(http.StatusInternalServerError, fmt.Errorf("%v", perr))
+		return recoverPanic(ctx, perr)
 	}
 
 	err := ctx.Err()
@@ -51,5 +62,60 @@ func checkError(ctx Context, perr any) error {
 		return err
 	}
 
-	return Error(http.StatusInternalServerError, err)
+	return Error(StatusOf(err), err)
+}
+
+// PanicError is the error when a handler panics. It's logged but not responded to clients.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// recoverPanic logs a recovered panic with the stack, and returns an error responding a generic message.
+// It panics again with `http.ErrAbortHandler`, to let the server abort the connection.
+func recoverPanic(ctx Context, perr any) error {
+	if perr == http.ErrAbortHandler {
+		panic(perr)
+	}
+
+	err := &PanicError{
+		Value: perr,
+		Stack: debug.Stack(),
+	}
+	ERROR(ctx, "panic when handling http", "panic", fmt.Sprint(perr), "stack", string(err.Stack))
+
+	message := http.StatusText(http.StatusInternalServerError)
+	if ModeModule.Value(ctx) == DevelopmentMode {
+		message = fmt.Sprintf("%v\n\n%s", err, err.Stack)
+	}
+
+	return &httpError{
+		Message: message,
+		code:    http.StatusInternalServerError,
+		err:     err,
+	}
+}
+
+// responseMessage returns the message of an error to respond in plain text.
+func responseMessage(err error) string {
+	var problem *ProblemDetails
+	if errors.As(err, &problem) {
+		return problem.Detail
+	}
+
+	var httpErr *httpError
+	if errors.As(err, &httpErr) {
+		return httpErr.Message
+	}
+
+	return err.Error()
 }
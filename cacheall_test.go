@@ -4,8 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"sync/atomic"
 	"testing"
 
@@ -20,6 +23,7 @@ func TestCacheAllMiddleware(t *testing.T) {
 		providers   []module.Provider
 		middlewares []espresso.HandleFunc
 		wantCode    int
+		wantType    string
 		wantBody    string
 	}{
 		{
@@ -28,6 +32,7 @@ func TestCacheAllMiddleware(t *testing.T) {
 				return errors.New("error")
 			}},
 			wantCode: http.StatusInternalServerError,
+			wantType: "text/plain; charset=utf-8",
 			wantBody: "error",
 		},
 		{
@@ -36,6 +41,7 @@ func TestCacheAllMiddleware(t *testing.T) {
 				return espresso.Error(http.StatusGatewayTimeout, errors.New("gateway timeout"))
 			}},
 			wantCode: http.StatusGatewayTimeout,
+			wantType: "text/plain; charset=utf-8",
 			wantBody: "gateway timeout",
 		},
 		{
@@ -44,7 +50,8 @@ func TestCacheAllMiddleware(t *testing.T) {
 				panic("panic")
 			}},
 			wantCode: http.StatusInternalServerError,
-			wantBody: "panic",
+			wantType: "text/plain; charset=utf-8",
+			wantBody: "Internal Server Error",
 		},
 		{
 			name:      "MiddlewareErrorWithCodec",
@@ -53,6 +60,7 @@ func TestCacheAllMiddleware(t *testing.T) {
 				return errors.New("error")
 			}},
 			wantCode: http.StatusInternalServerError,
+			wantType: "application/json; charset=utf-8",
 			wantBody: "{\"message\":\"error\"}\n",
 		},
 		{
@@ -62,6 +70,7 @@ func TestCacheAllMiddleware(t *testing.T) {
 				return espresso.Error(http.StatusGatewayTimeout, errors.New("gateway timeout"))
 			}},
 			wantCode: http.StatusGatewayTimeout,
+			wantType: "application/json; charset=utf-8",
 			wantBody: "{\"message\":\"gateway timeout\"}\n",
 		},
 		{
@@ -71,7 +80,8 @@ func TestCacheAllMiddleware(t *testing.T) {
 				panic("panic")
 			}},
 			wantCode: http.StatusInternalServerError,
-			wantBody: "{\"message\":\"panic\"}\n",
+			wantType: "application/json; charset=utf-8",
+			wantBody: "{\"message\":\"Internal Server Error\"}\n",
 		},
 	}
 
@@ -112,6 +122,10 @@ func TestCacheAllMiddleware(t *testing.T) {
 				t.Fatalf("resp.Status = %d, want: %d", got, want)
 			}
 
+			if got, want := resp.Header.Get("Content-Type"), tc.wantType; got != want {
+				t.Errorf("resp.Header[Content-Type] = %q, want: %q", got, want)
+			}
+
 			respBody, err := io.ReadAll(resp.Body)
 			if err != nil {
 				t.Fatal(err)
@@ -123,3 +137,129 @@ func TestCacheAllMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestCacheAllNotAcceptable(t *testing.T) {
+	var called atomic.Int32
+	espo := espresso.New()
+	espo.AddModule(espresso.ProvideCodecs)
+	espo.HandleFunc(espresso.RPC(func(ctx espresso.Context, req string) (string, error) {
+		if err := ctx.Endpoint(http.MethodPost, "/").End(); err != nil {
+			return "", err
+		}
+		called.Add(1)
+		return req, nil
+	}))
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	req, err := http.NewRequest(http.MethodPost, svr.URL, strings.NewReader(`"ok"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if got, want := resp.StatusCode, http.StatusNotAcceptable; got != want {
+		t.Fatalf("resp.Status = %d, want: %d", got, want)
+	}
+
+	if got, want := resp.Header.Get("Content-Type"), "application/json; charset=utf-8"; got != want {
+		t.Errorf("resp.Header[Content-Type] = %q, want: %q", got, want)
+	}
+
+	if got, want := resp.Header.Values("Vary"), []string{"Accept"}; !slices.Equal(got, want) {
+		t.Errorf("resp.Header[Vary] = %q, want: %q", got, want)
+	}
+
+	if got := called.Load(); got != 0 {
+		t.Errorf("handler is called %d times, want: not called", got)
+	}
+}
+
+func TestCacheAllPanic(t *testing.T) {
+	tests := []struct {
+		name      string
+		providers []module.Provider
+		wantBody  string
+	}{
+		{
+			name:     "Production",
+			wantBody: "Internal Server Error",
+		},
+		{
+			name:      "Development",
+			providers: []module.Provider{espresso.ProvideDevelopmentMode},
+			wantBody:  "panic: secret\n\ngoroutine ",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var logs strings.Builder
+			logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+			espo := espresso.New()
+			espo.AddModule(espresso.LogModule.ProvideValue(logger))
+			espo.AddModule(tc.providers...)
+			espo.HandleFunc(func(ctx espresso.Context) error {
+				if err := ctx.Endpoint(http.MethodGet, "/").End(); err != nil {
+					return err
+				}
+				panic("secret")
+			})
+
+			svr := httptest.NewServer(espo)
+			defer svr.Close()
+
+			resp, err := http.Get(svr.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := resp.StatusCode, http.StatusInternalServerError; got != want {
+				t.Errorf("resp.StatusCode = %d, want: %d", got, want)
+			}
+			if got, want := string(body), tc.wantBody; !strings.HasPrefix(got, want) {
+				t.Errorf("resp.Body = %q, want prefix: %q", got, want)
+			}
+
+			for _, want := range []string{"level=ERROR", "method=GET", "panic=secret", "stack=", "cacheall_test.go"} {
+				if !strings.Contains(logs.String(), want) {
+					t.Errorf("logs = %q, want containing: %q", logs.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestCacheAllAbortHandler(t *testing.T) {
+	espo := espresso.New()
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		if err := ctx.Endpoint(http.MethodGet, "/").End(); err != nil {
+			return err
+		}
+		panic(http.ErrAbortHandler)
+	})
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	resp, err := http.Get(svr.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatalf("http.Get() should fail with an aborted connection, got status %d", resp.StatusCode)
+	}
+}
@@ -1,11 +1,17 @@
 package espresso
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
+	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/googollee/module"
 	"gopkg.in/yaml.v3"
@@ -26,12 +32,15 @@ type Codec interface {
 
 type Codecs struct {
 	fallback Codec
+	list     []Codec
 	codecs   map[string]Codec
+	options  *DecodeOptions
 }
 
 func NewCodecs(codec ...Codec) *Codecs {
 	ret := &Codecs{
 		fallback: codec[0],
+		list:     codec,
 		codecs:   make(map[string]Codec),
 	}
 
@@ -42,66 +51,303 @@ func NewCodecs(codec ...Codec) *Codecs {
 	return ret
 }
 
+// WithDecodeOptions returns a copy of codecs decoding request bodies with `opts`, unless overridden by
+// `DecodeWith()`. It panics if a codec doesn't support `opts`, like `XML` with `DisallowUnknownFields`.
+func (c *Codecs) WithDecodeOptions(opts DecodeOptions) *Codecs {
+	for _, codec := range c.list {
+		if err := checkDecodeOptions(codec, opts); err != nil {
+			panic(err.Error())
+		}
+	}
+
+	ret := *c
+	ret.options = &opts
+	return &ret
+}
+
+// Mimes returns mime types of all codecs, in the order of adding.
+func (c *Codecs) Mimes() []string {
+	ret := make([]string, 0, len(c.list))
+	for _, codec := range c.list {
+		ret = append(ret, codec.Mime())
+	}
+	return ret
+}
+
+// DecodeRequest decodes the request body to `v`, with the codec matching the `Content-Type` header.
+// It returns an error with HTTP 415 if no codec supports the `Content-Type`, or HTTP 413 if the body is larger than
+// `DecodeOptions.MaxBodySize`.
 func (c *Codecs) DecodeRequest(ctx Context, v any) error {
-	codec := c.Request(ctx)
-	if err := codec.Decode(ctx, ctx.Request().Body, v); err != nil {
+	codec, err := c.requestCodec(ctx)
+	if err != nil {
+		return err
+	}
+
+	var decodeCtx context.Context = ctx
+	opts, ok := ctx.Value(decodeOptionsKey{}).(DecodeOptions)
+	if !ok && c.options != nil {
+		opts = *c.options
+		decodeCtx = withDecodeOptions(ctx, opts)
+	}
+
+	// Options set by `DecodeWith()` are unknown when configuring codecs, so check them here. It's an error of the
+	// server, not of the request.
+	if err := checkDecodeOptions(codec, opts); err != nil {
+		return Error(http.StatusInternalServerError, err)
+	}
+
+	body := ctx.Request().Body
+	if opts.MaxBodySize > 0 {
+		body = http.MaxBytesReader(ctx.ResponseWriter(), body, opts.MaxBodySize)
+	}
+
+	if err := codec.Decode(decodeCtx, body, v); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return errBodyTooLarge(maxBytesErr.Limit)
+		}
 		return fmt.Errorf("decode with codec(%s) error: %w", codec.Mime(), err)
 	}
 	return nil
 }
 
+// EncodeResponse encodes `v` to the response with HTTP 200, with the codec negotiated by the `Accept` header.
+// It returns an error with HTTP 406 if no codec is acceptable.
 func (c *Codecs) EncodeResponse(ctx Context, v any) error {
-	codec := c.Response(ctx)
-	if err := codec.Encode(ctx, ctx.ResponseWriter(), v); err != nil {
+	return c.EncodeResponseWithStatus(ctx, http.StatusOK, v)
+}
+
+// EncodeResponseWithStatus encodes `v` to the response with the status `code`, with the codec negotiated by the
+// `Accept` header. It sets `Content-Type` and `Content-Length` of the response.
+// It returns an error with HTTP 406 if no codec is acceptable, and writes nothing if encoding fails.
+func (c *Codecs) EncodeResponseWithStatus(ctx Context, code int, v any) error {
+	codec, err := c.Negotiate(ctx)
+	if err != nil {
+		return err
+	}
+
+	return encodeResponse(ctx, codec, code, v)
+}
+
+// Negotiate returns the codec negotiated by the `Accept` header, and adds `Vary: Accept` to the response.
+// It returns an error with HTTP 406 if no codec is acceptable. Handlers with side effects could call it before
+// handling, to fail early.
+func (c *Codecs) Negotiate(ctx Context) (Codec, error) {
+	varyAccept(ctx.ResponseWriter().Header())
+	return c.responseCodec(ctx)
+}
+
+func varyAccept(header http.Header) {
+	for _, v := range header.Values("Vary") {
+		for _, field := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), "Accept") {
+				return
+			}
+		}
+	}
+	header.Add("Vary", "Accept")
+}
+
+// Charseter is implemented by codecs encoding text, to add the `charset` parameter to `Content-Type`.
+type Charseter interface {
+	Charset() string
+}
+
+func encodeResponse(ctx Context, codec Codec, code int, v any) error {
+	var buf bytes.Buffer
+	if err := codec.Encode(ctx, &buf, v); err != nil {
 		return fmt.Errorf("encode with codec(%s) error: %w", codec.Mime(), err)
 	}
-	return nil
+
+	contentType := codec.Mime()
+	if _, ok := v.(*ProblemDetails); ok {
+		contentType = problemMime(contentType)
+	}
+	if charseter, ok := codec.(Charseter); ok {
+		contentType = mime.FormatMediaType(contentType, map[string]string{"charset": charseter.Charset()})
+	}
+
+	header := ctx.ResponseWriter().Header()
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Length", strconv.Itoa(buf.Len()))
+	ctx.ResponseWriter().WriteHeader(code)
+
+	_, err := buf.WriteTo(ctx.ResponseWriter())
+	return err
 }
 
+// Request returns the codec matching the `Content-Type` header of the request.
+// It returns the fallback codec if no codec matches.
 func (c *Codecs) Request(ctx Context) Codec {
-	ret := c.getCodec(ctx, "Context-Type")
-	if ret == nil {
+	ret, err := c.requestCodec(ctx)
+	if err != nil {
 		return c.fallback
 	}
 
 	return ret
 }
 
+// Response returns the codec negotiated by the `Accept` header of the request.
+// It returns the fallback codec if no codec is acceptable.
 func (c *Codecs) Response(ctx Context) Codec {
-	if ret := c.getCodec(ctx, "Accept"); ret != nil {
-		return ret
-	}
-
-	if ret := c.getCodec(ctx, "Context-Type"); ret != nil {
-		return ret
+	ret, err := c.responseCodec(ctx)
+	if err != nil {
+		return c.fallback
 	}
 
-	return c.fallback
+	return ret
 }
 
-func (c *Codecs) getCodec(ctx Context, head string) Codec {
-	req := ctx.Request()
-	reqMime, _, err := mime.ParseMediaType(req.Header.Get(head))
+func (c *Codecs) requestCodec(ctx Context) (Codec, error) {
+	contentType := ctx.Request().Header.Get("Content-Type")
+	if contentType == "" {
+		return c.fallback, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
 	if err != nil {
-		return nil
+		return nil, Error(http.StatusUnsupportedMediaType, fmt.Errorf("invalid Content-Type %q: %w", contentType, err))
 	}
 
-	ret, ok := c.codecs[reqMime]
+	ret, ok := c.codecs[mediaType]
 	if !ok {
-		return nil
+		return nil, Error(http.StatusUnsupportedMediaType, fmt.Errorf("not support Content-Type %q, supported: %s", mediaType, strings.Join(c.Mimes(), ", ")))
+	}
+
+	return ret, nil
+}
+
+// responseCodec negotiates the codec by the `Accept` header, following RFC 9110 section 12.5.1.
+// The codec with the highest quality wins. If qualities are equal, the codec matching an earlier media range in
+// `Accept` wins, and then the codec added earlier.
+// Without `Accept`, it uses the codec of the request body.
+func (c *Codecs) responseCodec(ctx Context) (Codec, error) {
+	accepts := ctx.Request().Header.Values("Accept")
+	if len(accepts) == 0 {
+		return c.requestCodec(ctx)
+	}
+
+	ranges := parseAccept(strings.Join(accepts, ","))
+
+	var ret Codec
+	bestQuality, bestOrder := 0.0, 0
+	for _, codec := range c.list {
+		quality, order, ok := ranges.match(codec.Mime())
+		if !ok || quality <= 0 {
+			continue
+		}
+
+		if ret == nil || quality > bestQuality || (quality == bestQuality && order < bestOrder) {
+			ret, bestQuality, bestOrder = codec, quality, order
+		}
+	}
+
+	if ret == nil {
+		return nil, Error(http.StatusNotAcceptable, fmt.Errorf("no acceptable codec for %q, supported: %s", strings.Join(accepts, ","), strings.Join(c.Mimes(), ", ")))
+	}
+
+	return ret, nil
+}
+
+type mediaRange struct {
+	typ     string
+	subtype string
+	quality float64
+}
+
+type mediaRanges []mediaRange
+
+func parseAccept(accept string) mediaRanges {
+	var ret mediaRanges
+	for _, item := range strings.Split(accept, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		mediaType, params, err := mime.ParseMediaType(item)
+		if err != nil {
+			continue
+		}
+
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			quality, err = strconv.ParseFloat(q, 64)
+			if err != nil || quality < 0 || quality > 1 {
+				continue
+			}
+		}
+
+		ret = append(ret, mediaRange{
+			typ:     typ,
+			subtype: subtype,
+			quality: quality,
+		})
 	}
 
 	return ret
 }
 
+// match returns the quality of the most specific range matching `mediaType`, and the index of that range.
+func (r mediaRanges) match(mediaType string) (quality float64, order int, ok bool) {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+
+	specific := 0
+	for i, mr := range r {
+		var s int
+		switch {
+		case mr.typ == typ && mr.subtype == subtype:
+			s = 3
+		case mr.typ == typ && mr.subtype == "*":
+			s = 2
+		case mr.typ == "*" && mr.subtype == "*":
+			s = 1
+		default:
+			continue
+		}
+
+		if s > specific {
+			specific, quality, order, ok = s, mr.quality, i, true
+		}
+	}
+
+	return
+}
+
 type JSON struct{}
 
 func (JSON) Mime() string {
 	return "application/json"
 }
 
+func (JSON) Charset() string {
+	return "utf-8"
+}
+
 func (JSON) Decode(ctx context.Context, r io.Reader, v any) error {
-	return json.NewDecoder(r).Decode(v)
+	opts := DecodeOptionsOf(ctx)
+
+	decoder := json.NewDecoder(r)
+	if opts.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	if opts.UseNumber {
+		decoder.UseNumber()
+	}
+
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	if opts.DisallowTrailingData {
+		return CheckTrailingData(io.MultiReader(decoder.Buffered(), r))
+	}
+	return nil
 }
 
 func (JSON) Encode(ctx context.Context, w io.Writer, v any) error {
@@ -114,8 +360,27 @@ func (YAML) Mime() string {
 	return "application/yaml"
 }
 
+func (YAML) Charset() string {
+	return "utf-8"
+}
+
 func (YAML) Decode(ctx context.Context, r io.Reader, v any) error {
-	return yaml.NewDecoder(r).Decode(v)
+	opts := DecodeOptionsOf(ctx)
+
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(opts.DisallowUnknownFields)
+
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	if opts.DisallowTrailingData {
+		var next yaml.Node
+		if err := decoder.Decode(&next); !errors.Is(err, io.EOF) {
+			return ErrTrailingData
+		}
+	}
+	return nil
 }
 
 func (YAML) Encode(ctx context.Context, w io.Writer, v any) error {
@@ -124,3 +389,73 @@ func (YAML) Encode(ctx context.Context, w io.Writer, v any) error {
 
 	return encoder.Encode(v)
 }
+
+type XML struct{}
+
+func (XML) Mime() string {
+	return "application/xml"
+}
+
+func (XML) Charset() string {
+	return "utf-8"
+}
+
+// ErrUnsupportedDecodeOption is returned by codecs not supporting a decode option set in `DecodeOptions`.
+var ErrUnsupportedDecodeOption = errors.New("unsupported decode option")
+
+// decodeOptionsChecker is implemented by codecs not supporting some decode options.
+type decodeOptionsChecker interface {
+	checkDecodeOptions(opts DecodeOptions) error
+}
+
+func checkDecodeOptions(codec Codec, opts DecodeOptions) error {
+	if checker, ok := codec.(decodeOptionsChecker); ok {
+		return checker.checkDecodeOptions(opts)
+	}
+	return nil
+}
+
+// `encoding/xml` doesn't support `DecodeOptions.DisallowUnknownFields`.
+func (XML) checkDecodeOptions(opts DecodeOptions) error {
+	if opts.DisallowUnknownFields {
+		return fmt.Errorf("xml codec: %w: DisallowUnknownFields", ErrUnsupportedDecodeOption)
+	}
+	return nil
+}
+
+// Decode decodes an XML document. It fails with `ErrUnsupportedDecodeOption` if `DecodeOptions.DisallowUnknownFields`
+// is set.
+func (XML) Decode(ctx context.Context, r io.Reader, v any) error {
+	opts := DecodeOptionsOf(ctx)
+	if err := (XML{}).checkDecodeOptions(opts); err != nil {
+		return err
+	}
+
+	decoder := xml.NewDecoder(r)
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	if opts.DisallowTrailingData {
+		for {
+			token, err := decoder.Token()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if data, ok := token.(xml.CharData); !ok || len(bytes.TrimSpace(data)) > 0 {
+				return ErrTrailingData
+			}
+		}
+	}
+	return nil
+}
+
+func (XML) Encode(ctx context.Context, w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(v)
+}
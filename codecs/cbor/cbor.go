@@ -0,0 +1,59 @@
+// Package cbor provides a codec of CBOR (RFC 8949) for espresso.
+package cbor
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/googollee/go-espresso"
+)
+
+var (
+	decMode       = mustDecMode(cbor.DecOptions{})
+	strictDecMode = mustDecMode(cbor.DecOptions{ExtraReturnErrors: cbor.ExtraDecErrorUnknownField})
+)
+
+func mustDecMode(opts cbor.DecOptions) cbor.DecMode {
+	ret, err := opts.DecMode()
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// Codec encodes and decodes values with the mime type `application/cbor`.
+// Struct fields are named by `cbor` tags, or by `json` tags if no `cbor` tag, so the same types work with
+// `espresso.JSON`.
+type Codec struct{}
+
+func (Codec) Mime() string {
+	return "application/cbor"
+}
+
+func (Codec) Decode(ctx context.Context, r io.Reader, v any) error {
+	opts := espresso.DecodeOptionsOf(ctx)
+
+	mode := decMode
+	if opts.DisallowUnknownFields {
+		mode = strictDecMode
+	}
+
+	decoder := mode.NewDecoder(r)
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	if opts.DisallowTrailingData {
+		if err := decoder.Decode(new(cbor.RawMessage)); !errors.Is(err, io.EOF) {
+			return espresso.ErrTrailingData
+		}
+	}
+	return nil
+}
+
+func (Codec) Encode(ctx context.Context, w io.Writer, v any) error {
+	return cbor.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,76 @@
+package cbor_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/googollee/go-espresso"
+	"github.com/googollee/go-espresso/codecs/cbor"
+)
+
+type book struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+func TestCodecRPC(t *testing.T) {
+	codec := cbor.Codec{}
+
+	espo := espresso.New()
+	espo.AddModule(espresso.CodecsModule.ProvideValue(espresso.NewCodecs(espresso.JSON{}, codec)))
+	espo.HandleFunc(espresso.RPC(func(ctx espresso.Context, req book) (*book, error) {
+		if err := ctx.Endpoint(http.MethodPost, "/books").End(); err != nil {
+			return nil, err
+		}
+		req.ID = 1
+		return &req, nil
+	}))
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	var body bytes.Buffer
+	if err := codec.Encode(context.Background(), &body, book{Title: "espresso"}); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, svr.URL+"/books", &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/cbor")
+	req.Header.Set("Accept", "application/cbor")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := resp.StatusCode, http.StatusOK; got != want {
+		t.Fatalf("resp.StatusCode = %d, want: %d, body: %q", got, want, respBody)
+	}
+	if got, want := resp.Header.Get("Content-Type"), "application/cbor"; got != want {
+		t.Errorf("resp.Header[Content-Type] = %q, want: %q", got, want)
+	}
+
+	var got map[string]any
+	if err := codec.Decode(context.Background(), bytes.NewReader(respBody), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := got["title"], "espresso"; got != want {
+		t.Errorf("resp[title] = %v, want: %v", got, want)
+	}
+	if got, want := got["id"], uint64(1); got != want {
+		t.Errorf("resp[id] = %#v, want: %#v", got, want)
+	}
+}
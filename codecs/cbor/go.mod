@@ -0,0 +1,15 @@
+module github.com/googollee/go-espresso/codecs/cbor
+
+go 1.22.5
+
+require (
+	github.com/fxamacker/cbor/v2 v2.7.0
+	github.com/googollee/go-espresso v0.1.0
+)
+
+require (
+	github.com/googollee/module v0.1.3 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,15 @@
+module github.com/googollee/go-espresso/codecs/msgpack
+
+go 1.22.5
+
+require (
+	github.com/googollee/go-espresso v0.1.0
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+)
+
+require (
+	github.com/googollee/module v0.1.3 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,47 @@
+// Package msgpack provides a codec of MessagePack for espresso.
+package msgpack
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/googollee/go-espresso"
+)
+
+// Codec encodes and decodes values with the mime type `application/msgpack`.
+// Struct fields are named by `msgpack` tags, or by `json` tags if no `msgpack` tag, so the same types work with
+// `espresso.JSON`.
+type Codec struct{}
+
+func (Codec) Mime() string {
+	return "application/msgpack"
+}
+
+func (Codec) Decode(ctx context.Context, r io.Reader, v any) error {
+	opts := espresso.DecodeOptionsOf(ctx)
+
+	decoder := msgpack.NewDecoder(r)
+	decoder.SetCustomStructTag("json")
+	decoder.DisallowUnknownFields(opts.DisallowUnknownFields)
+
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	if opts.DisallowTrailingData {
+		if _, err := decoder.PeekCode(); !errors.Is(err, io.EOF) {
+			return espresso.ErrTrailingData
+		}
+	}
+	return nil
+}
+
+func (Codec) Encode(ctx context.Context, w io.Writer, v any) error {
+	encoder := msgpack.NewEncoder(w)
+	encoder.SetCustomStructTag("json")
+
+	return encoder.Encode(v)
+}
@@ -0,0 +1,14 @@
+module github.com/googollee/go-espresso/codecs/protobuf
+
+go 1.22.5
+
+require (
+	github.com/googollee/go-espresso v0.1.0
+	google.golang.org/protobuf v1.36.6
+)
+
+require (
+	github.com/googollee/module v0.1.3 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,115 @@
+// Package protobuf provides a codec of Protocol Buffers for espresso.
+package protobuf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/googollee/go-espresso"
+)
+
+var (
+	// ErrNotMessage is returned when encoding or decoding a value not implementing `proto.Message`.
+	ErrNotMessage = errors.New("not a proto.Message")
+	// ErrUnknownFields is returned when decoding a message with unknown fields, if
+	// `espresso.DecodeOptions.DisallowUnknownFields` is set.
+	ErrUnknownFields = errors.New("unknown fields")
+)
+
+// Codec encodes and decodes values implementing `proto.Message`, with the mime type `application/x-protobuf`.
+type Codec struct{}
+
+func (Codec) Mime() string {
+	return "application/x-protobuf"
+}
+
+func (Codec) Decode(ctx context.Context, r io.Reader, v any) error {
+	msg, err := message(v, true)
+	if err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return err
+	}
+
+	if espresso.DecodeOptionsOf(ctx).DisallowUnknownFields {
+		return checkUnknownFields(msg.ProtoReflect())
+	}
+	return nil
+}
+
+func (Codec) Encode(ctx context.Context, w io.Writer, v any) error {
+	msg, err := message(v, false)
+	if err != nil {
+		return err
+	}
+
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+// message returns `v` as a `proto.Message`. `v` could be a message, or a pointer to a message pointer, like `&req`
+// in `espresso.RPC()` with a request type `*pb.Request`. A nil message pointer is allocated if `alloc` is true.
+func message(v any, alloc bool) (proto.Message, error) {
+	if msg, ok := v.(proto.Message); ok {
+		return msg, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() && rv.Elem().Kind() == reflect.Pointer {
+		elem := rv.Elem()
+		if elem.IsNil() && alloc {
+			elem.Set(reflect.New(elem.Type().Elem()))
+		}
+
+		if msg, ok := elem.Interface().(proto.Message); ok {
+			return msg, nil
+		}
+	}
+
+	return nil, fmt.Errorf("type %T: %w", v, ErrNotMessage)
+}
+
+// checkUnknownFields returns `ErrUnknownFields` if `msg` or any nested message has unknown fields.
+func checkUnknownFields(msg protoreflect.Message) error {
+	if len(msg.GetUnknown()) > 0 {
+		return fmt.Errorf("message %s: %w", msg.Descriptor().FullName(), ErrUnknownFields)
+	}
+
+	var err error
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList() && fd.Message() != nil:
+			list := v.List()
+			for i := 0; i < list.Len() && err == nil; i++ {
+				err = checkUnknownFields(list.Get(i).Message())
+			}
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			v.Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+				err = checkUnknownFields(v.Message())
+				return err == nil
+			})
+		case !fd.IsList() && !fd.IsMap() && fd.Message() != nil:
+			err = checkUnknownFields(v.Message())
+		}
+		return err == nil
+	})
+	return err
+}
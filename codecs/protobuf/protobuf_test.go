@@ -0,0 +1,171 @@
+package protobuf_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/googollee/go-espresso"
+	"github.com/googollee/go-espresso/codecs/protobuf"
+)
+
+func TestCodecRPC(t *testing.T) {
+	espo := espresso.New()
+	espo.AddModule(espresso.CodecsModule.ProvideValue(espresso.NewCodecs(espresso.JSON{}, protobuf.Codec{})))
+	espo.HandleFunc(espresso.RPC(func(ctx espresso.Context, req *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+		if err := ctx.Endpoint(http.MethodPost, "/echo").End(); err != nil {
+			return nil, err
+		}
+		return wrapperspb.String("hello " + req.GetValue()), nil
+	}))
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	body, err := proto.Marshal(wrapperspb.String("espresso"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, svr.URL+"/echo", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Accept", "application/x-protobuf")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := resp.StatusCode, http.StatusOK; got != want {
+		t.Fatalf("resp.StatusCode = %d, want: %d, body: %q", got, want, respBody)
+	}
+	if got, want := resp.Header.Get("Content-Type"), "application/x-protobuf"; got != want {
+		t.Errorf("resp.Header[Content-Type] = %q, want: %q", got, want)
+	}
+
+	var got wrapperspb.StringValue
+	if err := proto.Unmarshal(respBody, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := got.GetValue(), "hello espresso"; got != want {
+		t.Errorf("resp = %q, want: %q", got, want)
+	}
+}
+
+func TestCodecNotMessage(t *testing.T) {
+	var codec protobuf.Codec
+
+	var v struct{ Value string }
+	if err := codec.Decode(context.Background(), bytes.NewReader(nil), &v); !errors.Is(err, protobuf.ErrNotMessage) {
+		t.Errorf("Decode() = %v, want: %v", err, protobuf.ErrNotMessage)
+	}
+	if err := codec.Encode(context.Background(), io.Discard, &v); !errors.Is(err, protobuf.ErrNotMessage) {
+		t.Errorf("Encode() = %v, want: %v", err, protobuf.ErrNotMessage)
+	}
+}
+
+func TestCodecDisallowUnknownFields(t *testing.T) {
+	withUnknown := func(v *structpb.Value) *structpb.Value {
+		v.ProtoReflect().SetUnknown(protowire.AppendVarint(protowire.AppendTag(nil, 99, protowire.VarintType), 1))
+		return v
+	}
+
+	tests := []struct {
+		name     string
+		msg      *structpb.ListValue
+		wantCode int
+	}{
+		{
+			name: "OK",
+			msg: &structpb.ListValue{Values: []*structpb.Value{
+				structpb.NewStringValue("espresso"),
+			}},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "InList",
+			msg: &structpb.ListValue{Values: []*structpb.Value{
+				withUnknown(structpb.NewStringValue("espresso")),
+			}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "InMap",
+			msg: &structpb.ListValue{Values: []*structpb.Value{
+				structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{
+					"name": withUnknown(structpb.NewStringValue("espresso")),
+				}}),
+			}},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	codecs := espresso.NewCodecs(espresso.JSON{}, protobuf.Codec{}).WithDecodeOptions(espresso.DecodeOptions{
+		DisallowUnknownFields: true,
+	})
+
+	espo := espresso.New()
+	espo.AddModule(espresso.CodecsModule.ProvideValue(codecs))
+	espo.HandleFunc(espresso.RPCConsume(func(ctx espresso.Context, req *structpb.ListValue) error {
+		if err := ctx.Endpoint(http.MethodPost, "/list").End(); err != nil {
+			return err
+		}
+		return nil
+	}))
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := proto.Marshal(tc.msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := http.NewRequest(http.MethodPost, svr.URL+"/list", bytes.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/x-protobuf")
+			req.Header.Set("Accept", "application/json")
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			respBody, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := resp.StatusCode, tc.wantCode; got != want {
+				t.Errorf("resp.StatusCode = %d, want: %d, body: %q", got, want, respBody)
+			}
+			if tc.wantCode != http.StatusOK && !strings.Contains(string(respBody), protobuf.ErrUnknownFields.Error()) {
+				t.Errorf("resp.Body = %q, want containing: %q", respBody, protobuf.ErrUnknownFields)
+			}
+		})
+	}
+}
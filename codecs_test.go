@@ -2,8 +2,14 @@ package espresso
 
 import (
 	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 )
 
@@ -90,3 +96,310 @@ func TestCodecsDifferentContextTypeAccept(t *testing.T) {
 		t.Errorf("reqCodec.Mime() = %q, want: %q", got, want)
 	}
 }
+
+func TestCodecsNegotiation(t *testing.T) {
+	codecs := NewCodecs(JSON{}, YAML{})
+
+	tests := []struct {
+		name         string
+		contentType  string
+		accept       string
+		wantRequest  string
+		wantResponse string
+		wantCode     int
+	}{
+		{name: "YAMLBody", contentType: "application/yaml", wantRequest: "application/yaml", wantResponse: "application/yaml"},
+		{name: "ContentTypeParams", contentType: "application/json; charset=utf-8", wantRequest: "application/json", wantResponse: "application/json"},
+		{name: "UnsupportedContentType", contentType: "text/csv", wantCode: http.StatusUnsupportedMediaType},
+		{name: "InvalidContentType", contentType: "application/", wantCode: http.StatusUnsupportedMediaType},
+		{name: "AcceptQuality", accept: "application/json;q=0.5, application/yaml", wantRequest: "application/json", wantResponse: "application/yaml"},
+		{name: "AcceptWildcard", contentType: "application/yaml", accept: "application/*", wantRequest: "application/yaml", wantResponse: "application/json"},
+		{name: "AcceptAny", accept: "*/*", wantRequest: "application/json", wantResponse: "application/json"},
+		{name: "AcceptOrder", accept: "application/yaml, application/json", wantRequest: "application/json", wantResponse: "application/yaml"},
+		{name: "AcceptSpecificOverWildcard", accept: "application/*;q=0.8, application/json;q=0", wantRequest: "application/json", wantResponse: "application/yaml"},
+		{name: "NotAcceptable", accept: "text/html", wantRequest: "application/json", wantCode: http.StatusNotAcceptable},
+		{name: "NotAcceptableZeroQuality", accept: "*/*;q=0", wantRequest: "application/json", wantCode: http.StatusNotAcceptable},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "http://domain/path", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+
+			ctx := &runtimeContext{
+				ctx:      context.Background(),
+				request:  req,
+				response: httptest.NewRecorder(),
+			}
+
+			reqCodec, reqErr := codecs.requestCodec(ctx)
+			respCodec, respErr := codecs.responseCodec(ctx)
+
+			if tc.wantCode != 0 {
+				err := respErr
+				if tc.wantCode == http.StatusUnsupportedMediaType {
+					err = reqErr
+				}
+				var httpErr HTTPError
+				if !errors.As(err, &httpErr) {
+					t.Fatalf("error = %v, want: HTTPError", err)
+				}
+				if got, want := httpErr.HTTPCode(), tc.wantCode; got != want {
+					t.Errorf("HTTPCode() = %d, want: %d", got, want)
+				}
+				if tc.wantRequest == "" {
+					return
+				}
+			}
+
+			if reqErr != nil {
+				t.Fatalf("requestCodec() error: %v", reqErr)
+			}
+			if got, want := reqCodec.Mime(), tc.wantRequest; got != want {
+				t.Errorf("requestCodec().Mime() = %q, want: %q", got, want)
+			}
+			if tc.wantCode != 0 {
+				return
+			}
+
+			if respErr != nil {
+				t.Fatalf("responseCodec() error: %v", respErr)
+			}
+			if got, want := respCodec.Mime(), tc.wantResponse; got != want {
+				t.Errorf("responseCodec().Mime() = %q, want: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestCodecsEncodeResponseVary(t *testing.T) {
+	codecs := NewCodecs(JSON{}, YAML{})
+
+	req, err := http.NewRequest(http.MethodGet, "http://domain/path", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "application/yaml")
+	resp := httptest.NewRecorder()
+
+	ctx := &runtimeContext{
+		ctx:      context.Background(),
+		request:  req,
+		response: resp,
+	}
+
+	if err := codecs.EncodeResponse(ctx, map[string]int{"a": 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := resp.Header().Get("Vary"), "Accept"; got != want {
+		t.Errorf("Vary = %q, want: %q", got, want)
+	}
+	if got, want := resp.Body.String(), "a: 1\n"; got != want {
+		t.Errorf("body = %q, want: %q", got, want)
+	}
+}
+
+func TestCodecsEncodeResponseWithStatus(t *testing.T) {
+	codecs := NewCodecs(JSON{}, YAML{})
+
+	req, err := http.NewRequest(http.MethodPost, "http://domain/path", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := httptest.NewRecorder()
+
+	ctx := &runtimeContext{
+		ctx:      context.Background(),
+		request:  req,
+		response: resp,
+	}
+
+	if err := codecs.EncodeResponseWithStatus(ctx, http.StatusCreated, map[string]int{"a": 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := resp.Code, http.StatusCreated; got != want {
+		t.Errorf("status = %d, want: %d", got, want)
+	}
+	if got, want := resp.Header().Get("Content-Type"), "application/json; charset=utf-8"; got != want {
+		t.Errorf("Content-Type = %q, want: %q", got, want)
+	}
+	if got, want := resp.Header().Get("Content-Length"), "8"; got != want {
+		t.Errorf("Content-Length = %q, want: %q", got, want)
+	}
+	if got, want := resp.Body.String(), "{\"a\":1}\n"; got != want {
+		t.Errorf("body = %q, want: %q", got, want)
+	}
+}
+
+func TestCodecsEncodeResponseError(t *testing.T) {
+	codecs := NewCodecs(JSON{}, YAML{})
+
+	req, err := http.NewRequest(http.MethodPost, "http://domain/path", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := httptest.NewRecorder()
+
+	ctx := &runtimeContext{
+		ctx:      context.Background(),
+		request:  req,
+		response: resp,
+	}
+
+	if err := codecs.EncodeResponse(ctx, func() {}); err == nil {
+		t.Fatal("EncodeResponse() should fail")
+	}
+
+	if resp.Body.Len() != 0 || resp.Header().Get("Content-Type") != "" {
+		t.Errorf("response is written: %v, %q", resp.Header(), resp.Body.String())
+	}
+}
+
+func TestCodecsFormRPCConsume(t *testing.T) {
+	type Signup struct {
+		Name   string   `form:"name" validate:"minlen=2"`
+		Age    int      `form:"age,default=18"`
+		Tags   []string `form:"tag"`
+		Ignore string   `query:"ignore"`
+	}
+
+	espo := New()
+	espo.AddModule(CodecsModule.ProvideValue(NewCodecs(JSON{}, Form{}, XML{})))
+	espo.HandleFunc(RPCConsume(func(ctx Context, req Signup) error {
+		if err := ctx.Endpoint(http.MethodPost, "/signup").End(); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(ctx.ResponseWriter(), "%+v", req)
+		return nil
+	}))
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantCode    int
+		wantBody    string
+	}{
+		{
+			name:        "Form",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "name=espresso&tag=a&tag=b&ignore=1",
+			wantCode:    http.StatusOK,
+			wantBody:    "{Name:espresso Age:18 Tags:[a b] Ignore:}",
+		},
+		{
+			name:        "FormInvalid",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "name=e&age=abc",
+			wantCode:    http.StatusBadRequest,
+			wantBody:    `{"message":"can't decode request: decode with codec(application/x-www-form-urlencoded) error: bind form with name \"name\" to type string error: length must be at least 2, bind form with name \"age\" to type int error: strconv.ParseInt: parsing \"abc\": invalid syntax","errors":[{"field":"name","source":"form","message":"length must be at least 2"},{"field":"age","source":"form","message":"strconv.ParseInt: parsing \"abc\": invalid syntax"}]}` + "\n",
+		},
+		{
+			name:        "XML",
+			contentType: "application/xml",
+			body:        "<Signup><Name>espresso</Name><Age>20</Age></Signup>",
+			wantCode:    http.StatusOK,
+			wantBody:    "{Name:espresso Age:20 Tags:[] Ignore:}",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, svr.URL+"/signup", strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", tc.contentType)
+			req.Header.Set("Accept", "text/html, */*;q=0.8")
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := resp.StatusCode, tc.wantCode; got != want {
+				t.Errorf("resp.StatusCode = %d, want: %d", got, want)
+			}
+			if got, want := string(body), tc.wantBody; got != want {
+				t.Errorf("resp.Body = %q, want: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestCodecsFormEncode(t *testing.T) {
+	type Page struct {
+		Limit  int      `form:"limit"`
+		Cursor *string  `form:"cursor"`
+		Tags   []string `form:"tag"`
+		Ignore string
+	}
+
+	var buf strings.Builder
+	if err := (Form{}).Encode(context.Background(), &buf, &Page{Limit: 10, Tags: []string{"a", "b"}, Ignore: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "limit=10&tag=a&tag=b"; got != want {
+		t.Errorf("Encode() = %q, want: %q", got, want)
+	}
+
+	var values url.Values
+	if err := (Form{}).Decode(context.Background(), strings.NewReader(buf.String()), &values); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := values.Encode(), buf.String(); got != want {
+		t.Errorf("Decode() = %q, want: %q", got, want)
+	}
+}
+
+func TestCodecsXMLError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "Details",
+			err:  Error(http.StatusBadRequest, ValidationErrors{{Field: "name", Err: errors.New("too short")}}),
+			want: `<error><message>field &#34;name&#34;: too short</message><errors><error><field>name</field><source>body</source><message>too short</message></error></errors></error>`,
+		},
+		{
+			name: "NoDetails",
+			err:  Error(http.StatusNotFound, errors.New("not found")),
+			want: `<error><message>not found</message></error>`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf strings.Builder
+			if err := (XML{}).Encode(context.Background(), &buf, tc.err); err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := buf.String(), xml.Header+tc.want; got != want {
+				t.Errorf("Encode() = %q, want: %q", got, want)
+			}
+		})
+	}
+}
@@ -21,6 +21,10 @@ type Context interface {
 
 	Request() *http.Request
 	ResponseWriter() http.ResponseWriter
+
+	// Multipart returns a reader to stream parts of a `multipart/form-data` request.
+	// It can't be used with `BindFile()` or `BindForm()` on the same request.
+	Multipart() (*MultipartReader, error)
 }
 
 type MiddlewareProvider interface {
@@ -0,0 +1,87 @@
+package espresso
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DecodeOptions configures decoding request bodies. Codecs read options with `DecodeOptionsOf()`.
+type DecodeOptions struct {
+	// MaxBodySize is the max bytes of a request body. A larger body fails with HTTP 413. 0 means no limit.
+	MaxBodySize int64
+	// DisallowUnknownFields fails decoding if the body has fields not in the target struct.
+	DisallowUnknownFields bool
+	// UseNumber decodes numbers in `any` values as `json.Number` instead of `float64`, for codecs supporting it.
+	UseNumber bool
+	// DisallowTrailingData fails decoding if the body has data after the first value.
+	DisallowTrailingData bool
+}
+
+// ErrTrailingData is the error when a request body has data after the decoded value.
+var ErrTrailingData = errors.New("trailing data after the body")
+
+type decodeOptionsKey struct{}
+
+// DecodeWith returns a middleware to decode request bodies with `opts` in following handlers.
+// It overrides options set by `Codecs.WithDecodeOptions()` or previous `DecodeWith()`. Use it with
+// `Espresso.Use()`, `Router.Use()`, or as an endpoint middleware in `Context.Endpoint()`.
+func DecodeWith(opts DecodeOptions) HandleFunc {
+	return func(ctx Context) error {
+		ctx = ctx.WithParent(withDecodeOptions(ctx, opts))
+		ctx.Next()
+
+		return ctx.Err()
+	}
+}
+
+func withDecodeOptions(ctx context.Context, opts DecodeOptions) context.Context {
+	return context.WithValue(ctx, decodeOptionsKey{}, opts)
+}
+
+// DecodeOptionsOf returns decode options in `ctx`, which is passed to `Codec.Decode()`.
+func DecodeOptionsOf(ctx context.Context) DecodeOptions {
+	opts, _ := ctx.Value(decodeOptionsKey{}).(DecodeOptions)
+	return opts
+}
+
+// requestDecodeOptions returns decode options of the request, set by `DecodeWith()` or `Codecs.WithDecodeOptions()`.
+func requestDecodeOptions(ctx context.Context) DecodeOptions {
+	if opts, ok := ctx.Value(decodeOptionsKey{}).(DecodeOptions); ok {
+		return opts
+	}
+	if codecs := CodecsModule.Value(ctx); codecs != nil && codecs.options != nil {
+		return *codecs.options
+	}
+	return DecodeOptions{}
+}
+
+// errBodyTooLarge is the error with HTTP 413 when a request body is larger than `limit`.
+func errBodyTooLarge(limit int64) error {
+	return Error(http.StatusRequestEntityTooLarge, fmt.Errorf("request body is larger than %d bytes", limit))
+}
+
+// CheckTrailingData returns `ErrTrailingData` if `r` has data other than white spaces. Codecs could call it after
+// decoding a value, with the remaining data of their decoders.
+func CheckTrailingData(r io.Reader) error {
+	var buf [512]byte
+	for {
+		n, err := r.Read(buf[:])
+		for _, b := range buf[:n] {
+			switch b {
+			case ' ', '\t', '\r', '\n':
+			default:
+				return ErrTrailingData
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package espresso_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/googollee/go-espresso"
+)
+
+func TestDecodeOptions(t *testing.T) {
+	type Book struct {
+		Title string `json:"title" yaml:"title"`
+		Extra any    `json:"extra" yaml:"extra"`
+	}
+
+	handler := func(ctx espresso.Context, book Book) error {
+		fmt.Fprintf(ctx.ResponseWriter(), "%s %T", book.Title, book.Extra)
+		return nil
+	}
+
+	codecs := espresso.NewCodecs(espresso.JSON{}, espresso.YAML{}).WithDecodeOptions(espresso.DecodeOptions{
+		DisallowUnknownFields: true,
+		DisallowTrailingData:  true,
+	})
+
+	espo := espresso.New()
+	espo.AddModule(espresso.CodecsModule.ProvideValue(codecs))
+	espo.HandleFunc(espresso.RPCConsume(func(ctx espresso.Context, book Book) error {
+		if err := ctx.Endpoint(http.MethodPost, "/strict").End(); err != nil {
+			return err
+		}
+		return handler(ctx, book)
+	}))
+	espo.HandleFunc(espresso.RPCConsume(func(ctx espresso.Context, book Book) error {
+		if err := ctx.Endpoint(http.MethodPost, "/limited", espresso.DecodeWith(espresso.DecodeOptions{
+			MaxBodySize: 32,
+			UseNumber:   true,
+		})).End(); err != nil {
+			return err
+		}
+		return handler(ctx, book)
+	}))
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		wantCode    int
+		wantBody    string
+	}{
+		{
+			name:        "StrictOK",
+			path:        "/strict",
+			contentType: "application/json",
+			body:        `{"title":"espresso","extra":1} `,
+			wantCode:    http.StatusOK,
+			wantBody:    "espresso float64",
+		},
+		{
+			name:        "StrictUnknownField",
+			path:        "/strict",
+			contentType: "application/json",
+			body:        `{"title":"espresso","titel":"typo"}`,
+			wantCode:    http.StatusBadRequest,
+			wantBody:    `{"message":"can't decode request: decode with codec(application/json) error: json: unknown field \"titel\""}` + "\n",
+		},
+		{
+			name:        "StrictTrailingData",
+			path:        "/strict",
+			contentType: "application/json",
+			body:        `{"title":"espresso"}{}`,
+			wantCode:    http.StatusBadRequest,
+			wantBody:    `{"message":"can't decode request: decode with codec(application/json) error: trailing data after the body"}` + "\n",
+		},
+		{
+			name:        "StrictYAMLUnknownField",
+			path:        "/strict",
+			contentType: "application/yaml",
+			body:        "title: espresso\ntitel: typo\n",
+			wantCode:    http.StatusBadRequest,
+			wantBody:    "message: |-\n    can't decode request: decode with codec(application/yaml) error: yaml: unmarshal errors:\n      line 2: field titel not found in type espresso_test.Book\n",
+		},
+		{
+			name:        "EndpointOverride",
+			path:        "/limited",
+			contentType: "application/json",
+			body:        `{"title":"espresso","extra":1,"titel":""}`,
+			wantCode:    http.StatusRequestEntityTooLarge,
+			wantBody:    `{"message":"request body is larger than 32 bytes"}` + "\n",
+		},
+		{
+			name:        "EndpointUseNumber",
+			path:        "/limited",
+			contentType: "application/json",
+			body:        `{"title":"espresso","extra":1}`,
+			wantCode:    http.StatusOK,
+			wantBody:    "espresso json.Number",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Post(svr.URL+tc.path, tc.contentType, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := resp.StatusCode, tc.wantCode; got != want {
+				t.Errorf("resp.StatusCode = %d, want: %d", got, want)
+			}
+			if got, want := string(body), tc.wantBody; got != want {
+				t.Errorf("resp.Body = %q, want: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeOptionsUnsupported(t *testing.T) {
+	t.Run("WithDecodeOptions", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("WithDecodeOptions(DisallowUnknownFields) with XML doesn't panic")
+			}
+		}()
+		espresso.NewCodecs(espresso.JSON{}, espresso.XML{}).WithDecodeOptions(espresso.DecodeOptions{
+			DisallowUnknownFields: true,
+		})
+	})
+
+	t.Run("DecodeWith", func(t *testing.T) {
+		type Book struct {
+			Title string
+		}
+
+		espo := espresso.New()
+		espo.AddModule(espresso.CodecsModule.ProvideValue(espresso.NewCodecs(espresso.JSON{}, espresso.XML{})))
+		espo.HandleFunc(espresso.RPCConsume(func(ctx espresso.Context, book Book) error {
+			if err := ctx.Endpoint(http.MethodPost, "/strict", espresso.DecodeWith(espresso.DecodeOptions{
+				DisallowUnknownFields: true,
+			})).End(); err != nil {
+				return err
+			}
+			return nil
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, "/strict", strings.NewReader("<Book><Title>espresso</Title></Book>"))
+		req.Header.Set("Content-Type", "application/xml")
+		resp := httptest.NewRecorder()
+		espo.ServeHTTP(resp, req)
+
+		if got, want := resp.Code, http.StatusInternalServerError; got != want {
+			t.Errorf("resp.Code = %d, want: %d, body: %q", got, want, resp.Body.String())
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package espresso
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+//go:embed assets/explorer.html
+var explorerHTML []byte
+
+// Docs serves the OpenAPI document of an `Espresso` server and an HTML explorer of it.
+// Register it with `HandleAll()` under a prefix:
+//
+//	svr.WithPrefix("/_docs").HandleAll(espresso.DocsHandler(svr))
+//
+// It serves:
+//   - `/_docs/`: the explorer, which is self-contained without loading anything from CDNs.
+//   - `/_docs/openapi.json`: the document in JSON.
+//   - `/_docs/openapi.yaml`: the document in YAML.
+type Docs struct {
+	svr *Espresso
+}
+
+// DocsHandler creates a `Docs` serving documents of `svr`.
+func DocsHandler(svr *Espresso) *Docs {
+	return &Docs{
+		svr: svr,
+	}
+}
+
+func (d *Docs) Explorer(ctx Context) error {
+	if err := ctx.Endpoint(http.MethodGet, "/{$}").
+		End(); err != nil {
+		return err
+	}
+
+	w := ctx.ResponseWriter()
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, err := w.Write(explorerHTML)
+	return err
+}
+
+func (d *Docs) OpenAPIJSON(ctx Context) error {
+	if err := ctx.Endpoint(http.MethodGet, "/openapi.json").
+		End(); err != nil {
+		return err
+	}
+
+	return d.serve(ctx, JSON{})
+}
+
+func (d *Docs) OpenAPIYAML(ctx Context) error {
+	if err := ctx.Endpoint(http.MethodGet, "/openapi.yaml").
+		End(); err != nil {
+		return err
+	}
+
+	return d.serve(ctx, YAML{})
+}
+
+func (d *Docs) serve(ctx Context, codec Codec) error {
+	w := ctx.ResponseWriter()
+	w.Header().Set("Content-Type", codec.Mime()+"; charset=utf-8")
+	return codec.Encode(ctx, w, d.svr.OpenAPI())
+}
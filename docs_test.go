@@ -0,0 +1,96 @@
+package espresso_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/googollee/go-espresso"
+)
+
+func TestDocsHandler(t *testing.T) {
+	espo := espresso.New()
+	espo.WithPrefix("/v1").HandleAll(openAPIService{})
+	espo.WithPrefix("/_docs").HandleAll(espresso.DocsHandler(espo))
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	get := func(t *testing.T, path string) (*http.Response, []byte) {
+		resp, err := http.Get(svr.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("GET %s status = %d, want: %d", path, got, want)
+		}
+
+		return resp, body
+	}
+
+	t.Run("Explorer", func(t *testing.T) {
+		resp, body := get(t, "/_docs/")
+
+		if got, want := resp.Header.Get("Content-Type"), "text/html; charset=utf-8"; got != want {
+			t.Errorf("Content-Type = %q, want: %q", got, want)
+		}
+		if !strings.Contains(string(body), `fetch("openapi.json")`) {
+			t.Errorf("explorer doesn't load openapi.json")
+		}
+		if strings.Contains(string(body), "https://") {
+			t.Errorf("explorer should not load resources from other sites")
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		resp, body := get(t, "/_docs/openapi.json")
+
+		if got, want := resp.Header.Get("Content-Type"), "application/json; charset=utf-8"; got != want {
+			t.Errorf("Content-Type = %q, want: %q", got, want)
+		}
+
+		var doc struct {
+			Paths map[string]any `json:"paths"`
+		}
+		if err := json.Unmarshal(body, &doc); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := doc.Paths["/v1/books/{id}"]; !ok {
+			t.Errorf("paths = %v, want /v1/books/{id}", doc.Paths)
+		}
+	})
+
+	t.Run("YAML", func(t *testing.T) {
+		resp, body := get(t, "/_docs/openapi.yaml")
+
+		if got, want := resp.Header.Get("Content-Type"), "application/yaml; charset=utf-8"; got != want {
+			t.Errorf("Content-Type = %q, want: %q", got, want)
+		}
+
+		var doc struct {
+			OpenAPI string         `yaml:"openapi"`
+			Paths   map[string]any `yaml:"paths"`
+		}
+		if err := yaml.Unmarshal(body, &doc); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := doc.OpenAPI, "3.1.0"; got != want {
+			t.Errorf("openapi = %q, want: %q", got, want)
+		}
+		if _, ok := doc.Paths["/v1/books/{id}"]; !ok {
+			t.Errorf("paths = %v, want /v1/books/{id}", doc.Paths)
+		}
+	})
+}
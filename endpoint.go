@@ -1,9 +1,17 @@
 package espresso
 
-import "reflect"
+import (
+	"fmt"
+	"reflect"
+)
 
 type EndpointBuilder interface {
-	BindPath(key string, v any) EndpointBuilder
+	BindPath(key string, v any, opts ...BindOption) EndpointBuilder
+	BindQuery(key string, v any, opts ...BindOption) EndpointBuilder
+	BindForm(key string, v any, opts ...BindOption) EndpointBuilder
+	BindHead(key string, v any, opts ...BindOption) EndpointBuilder
+	BindFile(key string, v any, opts ...BindOption) EndpointBuilder
+	BindStruct(v any) EndpointBuilder
 	End() BindErrors
 }
 
@@ -14,9 +22,12 @@ type Endpoint struct {
 	QueryParams  map[string]BindParam
 	FormParams   map[string]BindParam
 	HeadParams   map[string]BindParam
+	FileParams   map[string]BindParam
 	RequestType  reflect.Type
 	ResponseType reflect.Type
 	ChainFuncs   []HandleFunc
+
+	structs map[reflect.Type][]structField
 }
 
 func newEndpoint() *Endpoint {
@@ -25,5 +36,23 @@ func newEndpoint() *Endpoint {
 		QueryParams: make(map[string]BindParam),
 		FormParams:  make(map[string]BindParam),
 		HeadParams:  make(map[string]BindParam),
+		FileParams:  make(map[string]BindParam),
+		structs:     make(map[reflect.Type][]structField),
+	}
+}
+
+func (e *Endpoint) params(src BindSource) map[string]BindParam {
+	switch src {
+	case BindPathParam:
+		return e.PathParams
+	case BindQueryParam:
+		return e.QueryParams
+	case BindFormParam:
+		return e.FormParams
+	case BindHeadParam:
+		return e.HeadParams
+	case BindFileParam:
+		return e.FileParams
 	}
+	panic(fmt.Sprintf("not support bind type %d", src))
 }
@@ -1,20 +1,84 @@
 package espresso
 
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+)
+
 type HTTPError interface {
 	HTTPCode() int
 }
 
 func Error(code int, err error) error {
-	return &httpError{
+	ret := &httpError{
 		Message: err.Error(),
 
 		err:  err,
 		code: code,
 	}
+
+	var details errorDetailer
+	if errors.As(err, &details) {
+		ret.Errors = details.errorDetails()
+	}
+
+	return ret
+}
+
+// StatusOf returns the HTTP status code responded for the error `err` returned by handlers.
+// It's the code of `HTTPError`, 400 for binding and validation errors, 413 for binding from a too large body, and 500
+// for other errors.
+func StatusOf(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	if coder, ok := err.(HTTPError); ok {
+		return coder.HTTPCode()
+	}
+
+	var bindErrs BindErrors
+	if errors.As(err, &bindErrs) {
+		return bindErrs.status()
+	}
+
+	var details errorDetailer
+	if errors.As(err, &details) {
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
+}
+
+// ErrorDetail describes an invalid field or param in a request.
+type ErrorDetail struct {
+	Field   string `json:"field" xml:"field"`
+	Source  string `json:"source" xml:"source"`
+	Message string `json:"message" xml:"message"`
+}
+
+type errorDetailer interface {
+	errorDetails() []ErrorDetail
+}
+
+// errorDetailList is details of an error, wrapped in `<errors>` in XML. The wrapper is omitted without details, which
+// the `xml:"errors>error,omitempty"` tag doesn't do.
+type errorDetailList []ErrorDetail
+
+func (l errorDetailList) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(struct {
+		Errors []ErrorDetail `xml:"error"`
+	}{l}, start)
 }
 
 type httpError struct {
-	Message string `json:"message"`
+	XMLName xml.Name        `json:"-" yaml:"-" xml:"error"`
+	Code    string          `json:"code,omitempty" yaml:",omitempty" xml:"code,omitempty"`
+	Message string          `json:"message" xml:"message"`
+	Errors  errorDetailList `json:"errors,omitempty" yaml:",omitempty" xml:"errors,omitempty"`
+	// RequestID is set when responding, if request IDs are enabled.
+	RequestID string `json:"request_id,omitempty" yaml:"request_id,omitempty" xml:"request_id,omitempty"`
 
 	code int
 	err  error
@@ -0,0 +1,98 @@
+package espresso
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrorDefinition defines an error with a stable code, which clients could branch on.
+// Errors returned by `New()` and `Wrap()` match the definition with `errors.Is()`.
+type ErrorDefinition struct {
+	Code    string
+	Status  int
+	Message string
+}
+
+var errorCatalog sync.Map // map[string]*ErrorDefinition
+
+// DefineError defines an error with the `code`, responding with the HTTP `status` and a message formatted from
+// `messageTemplate` with `fmt.Sprintf()`. Definitions are listed in generated OpenAPI documents.
+// It panics if the `code` is defined twice.
+//
+//	var ErrBookNotFound = espresso.DefineError("BOOK_NOT_FOUND", http.StatusNotFound, "book %d not found")
+//
+//	return ErrBookNotFound.New(id)
+func DefineError(code string, status int, messageTemplate string) *ErrorDefinition {
+	ret := &ErrorDefinition{
+		Code:    code,
+		Status:  status,
+		Message: messageTemplate,
+	}
+
+	if _, loaded := errorCatalog.LoadOrStore(code, ret); loaded {
+		panic(fmt.Sprintf("error code %q is defined twice", code))
+	}
+
+	return ret
+}
+
+// ErrorCatalog returns all defined errors, sorted by codes.
+func ErrorCatalog() []*ErrorDefinition {
+	var ret []*ErrorDefinition
+	errorCatalog.Range(func(_, v any) bool {
+		ret = append(ret, v.(*ErrorDefinition))
+		return true
+	})
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Code < ret[j].Code
+	})
+	return ret
+}
+
+func (d *ErrorDefinition) Error() string {
+	return d.Code
+}
+
+// New returns an error of the definition, with the message formatted with `args`.
+func (d *ErrorDefinition) New(args ...any) error {
+	return d.Wrap(nil, args...)
+}
+
+// Wrap returns an error of the definition caused by `err`, with the message formatted with `args`.
+// The error matches both the definition and `err` with `errors.Is()`.
+func (d *ErrorDefinition) Wrap(err error, args ...any) error {
+	defined := &definedError{
+		def:     d,
+		message: fmt.Sprintf(d.Message, args...),
+		cause:   err,
+	}
+
+	return &httpError{
+		Code:    d.Code,
+		Message: defined.message,
+
+		code: d.Status,
+		err:  defined,
+	}
+}
+
+type definedError struct {
+	def     *ErrorDefinition
+	message string
+	cause   error
+}
+
+func (e *definedError) Error() string {
+	if e.cause == nil {
+		return e.message
+	}
+	return fmt.Sprintf("%s: %v", e.message, e.cause)
+}
+
+func (e *definedError) Unwrap() []error {
+	if e.cause == nil {
+		return []error{e.def}
+	}
+	return []error{e.def, e.cause}
+}
@@ -0,0 +1,92 @@
+package espresso_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/googollee/go-espresso"
+)
+
+var (
+	errBookNotFound   = espresso.DefineError("BOOK_NOT_FOUND", http.StatusNotFound, "book %d not found")
+	errBookOutOfStock = espresso.DefineError("BOOK_OUT_OF_STOCK", http.StatusConflict, "book %q is out of stock")
+)
+
+func TestDefineError(t *testing.T) {
+	errDB := errors.New("db error")
+
+	espo := espresso.New()
+	espo.AddModule(espresso.ProvideCodecs)
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		var id int
+		if err := ctx.Endpoint(http.MethodGet, "/books/{id}").
+			BindPath("id", &id).
+			End(); err != nil {
+			return err
+		}
+
+		var err error
+		switch id {
+		case 1:
+			err = errBookNotFound.New(id)
+		default:
+			err = errBookOutOfStock.Wrap(errDB, "espresso")
+		}
+
+		if !errors.Is(err, errBookNotFound) && !errors.Is(err, errBookOutOfStock) {
+			t.Errorf("errors.Is(%v, definition) = false, want: true", err)
+		}
+		if id != 1 && !errors.Is(err, errDB) {
+			t.Errorf("errors.Is(%v, errDB) = false, want: true", err)
+		}
+
+		return err
+	})
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	tests := []struct {
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{"/books/1", http.StatusNotFound, `{"code":"BOOK_NOT_FOUND","message":"book 1 not found"}` + "\n"},
+		{"/books/2", http.StatusConflict, `{"code":"BOOK_OUT_OF_STOCK","message":"book \"espresso\" is out of stock"}` + "\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			resp, err := http.Get(svr.URL + tc.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := resp.StatusCode, tc.wantCode; got != want {
+				t.Errorf("resp.StatusCode = %d, want: %d", got, want)
+			}
+			if got, want := string(body), tc.wantBody; got != want {
+				t.Errorf("resp.Body = %q, want: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestDefineErrorTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("DefineError() with a defined code should panic")
+		}
+	}()
+
+	espresso.DefineError("BOOK_NOT_FOUND", http.StatusNotFound, "")
+}
@@ -34,8 +34,8 @@ func ExampleEspresso() {
 	// Log to stdout for Output
 	espo.AddModule(espresso.LogModule.ProvideWithFunc(func(ctx context.Context) (*slog.Logger, error) {
 		removeTime := func(groups []string, a slog.Attr) slog.Attr {
-			// Remove time from the output for predictable test output.
-			if a.Key == slog.TimeKey {
+			// Remove time and duration from the output for predictable test output.
+			if a.Key == slog.TimeKey || a.Key == "duration" {
 				return slog.Attr{}
 			}
 			return a
@@ -142,10 +142,10 @@ func ExampleEspresso() {
 
 	// Output:
 	// level=INFO msg="receive http" method=GET path=/http/book/1
-	// level=INFO msg="finish http" method=GET path=/http/book/1
+	// level=INFO msg="finish http" method=GET path=/http/book/1 route=/http/book/{id} status=200 bytes=37 remote_ip=127.0.0.1 user_agent=Go-http-client/1.1
 	// Book 1 title: The Espresso Book
 	// level=INFO msg="receive http" method=POST path=/http/book
-	// level=INFO msg="finish http" method=POST path=/http/book
+	// level=INFO msg="finish http" method=POST path=/http/book route=/http/book status=200 bytes=32 remote_ip=127.0.0.1 user_agent=Go-http-client/1.1
 	// The New Book id: 2
 }
 
@@ -170,8 +170,8 @@ func ExampleEspresso_rpc() {
 	// Log to stdout for Output
 	espo.AddModule(espresso.LogModule.ProvideWithFunc(func(ctx context.Context) (*slog.Logger, error) {
 		removeTime := func(groups []string, a slog.Attr) slog.Attr {
-			// Remove time from the output for predictable test output.
-			if a.Key == slog.TimeKey {
+			// Remove time and duration from the output for predictable test output.
+			if a.Key == slog.TimeKey || a.Key == "duration" {
 				return slog.Attr{}
 			}
 			return a
@@ -262,9 +262,9 @@ func ExampleEspresso_rpc() {
 
 	// Output:
 	// level=INFO msg="receive http" method=GET path=/rpc/book/1
-	// level=INFO msg="finish http" method=GET path=/rpc/book/1
+	// level=INFO msg="finish http" method=GET path=/rpc/book/1 route=/rpc/book/{id} status=200 bytes=37 remote_ip=127.0.0.1 user_agent=Go-http-client/1.1
 	// Book 1 title: The Espresso Book
 	// level=INFO msg="receive http" method=POST path=/rpc/book
-	// level=INFO msg="finish http" method=POST path=/rpc/book
+	// level=INFO msg="finish http" method=POST path=/rpc/book route=/rpc/book status=200 bytes=32 remote_ip=127.0.0.1 user_agent=Go-http-client/1.1
 	// The New Book id: 2
 }
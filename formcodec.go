@@ -0,0 +1,207 @@
+package espresso
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Form encodes and decodes values with the mime type `application/x-www-form-urlencoded`.
+//
+// It decodes to `url.Values`, or to a struct with `form` tags, binding fields the same way as
+// `EndpointBuilder.BindForm()` and `EndpointBuilder.BindStruct()`, including tag options and `validate` rules.
+// It encodes `url.Values`, or a struct with `form` tags.
+type Form struct{}
+
+func (Form) Mime() string {
+	return "application/x-www-form-urlencoded"
+}
+
+func (Form) Charset() string {
+	return "utf-8"
+}
+
+func (Form) Decode(ctx context.Context, r io.Reader, v any) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return err
+	}
+
+	if ret, ok := v.(*url.Values); ok {
+		*ret = values
+		return nil
+	}
+
+	rv, err := formStruct(v)
+	if err != nil {
+		return err
+	}
+
+	fields, err := formFields(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	if DecodeOptionsOf(ctx).DisallowUnknownFields {
+		if err := checkUnknownFormFields(values, fields); err != nil {
+			return err
+		}
+	}
+
+	var errs BindErrors
+	base := rv.Addr().UnsafePointer()
+	for _, field := range fields {
+		if err := field.set(base, values[field.param.Key]); err != nil {
+			errs = append(errs, errorBind(field.param, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func checkUnknownFormFields(values url.Values, fields []structField) error {
+	known := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		known[field.param.Key] = true
+	}
+
+	var unknown []string
+	for key := range values {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+	return fmt.Errorf("unknown fields: %s", strings.Join(unknown, ", "))
+}
+
+func (Form) Encode(ctx context.Context, w io.Writer, v any) error {
+	values, err := formValues(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, values.Encode())
+	return err
+}
+
+func formValues(v any) (url.Values, error) {
+	switch v := v.(type) {
+	case url.Values:
+		return v, nil
+	case *url.Values:
+		return *v, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Struct {
+		ptr := reflect.New(rv.Type())
+		ptr.Elem().Set(rv)
+		v = ptr.Interface()
+	}
+
+	rv, err := formStruct(v)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := formFields(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make(url.Values)
+	base := rv.Addr().UnsafePointer()
+	for _, field := range fields {
+		fv := reflect.ValueOf(field.pointer(base)).Elem()
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+
+		if fv.Kind() != reflect.Slice || fv.Type().Implements(textMarshalerType) {
+			fv = reflect.Append(reflect.MakeSlice(reflect.SliceOf(fv.Type()), 0, 1), fv)
+		}
+
+		for i := 0; i < fv.Len(); i++ {
+			str, err := formatFormValue(fv.Index(i))
+			if err != nil {
+				return nil, fmt.Errorf("encode form field %q: %w", field.param.Key, err)
+			}
+			ret.Add(field.param.Key, str)
+		}
+	}
+
+	return ret, nil
+}
+
+func formatFormValue(v reflect.Value) (string, error) {
+	if marshaler, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+
+	return fmt.Sprint(v.Interface()), nil
+}
+
+// formStruct returns the struct which `v` points to. `v` could be a pointer to a struct, or a pointer to a struct
+// pointer, like `&req` in `RPC()` with a request type `*Request`. A nil struct pointer is allocated.
+func formStruct(v any) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() && rv.Elem().Kind() == reflect.Pointer {
+		if rv.Elem().IsNil() {
+			rv.Elem().Set(reflect.New(rv.Elem().Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("form codec: need a pointer to a struct or url.Values, got %T", v)
+	}
+
+	return rv.Elem(), nil
+}
+
+var formFieldsCache sync.Map // map[reflect.Type][]structField
+
+// formFields returns fields with `form` tags of the struct type `t`. Fields are parsed once and cached.
+func formFields(t reflect.Type) ([]structField, error) {
+	if fields, ok := formFieldsCache.Load(t); ok {
+		return fields.([]structField), nil
+	}
+
+	fields, err := newStructFields(reflect.New(t).Interface(), "")
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []structField
+	for _, field := range fields {
+		if field.param.From == BindFormParam {
+			ret = append(ret, field)
+		}
+	}
+
+	formFieldsCache.Store(t, ret)
+	return ret, nil
+}
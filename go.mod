@@ -2,10 +2,12 @@ module github.com/googollee/go-espresso
 
 go 1.22.5
 
-require gopkg.in/yaml.v3 v3.0.1
+require (
+	github.com/googollee/module v0.1.3
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
-	github.com/googollee/module v0.1.3 // indirect
 	github.com/kr/pretty v0.3.1 // indirect
 	github.com/rogpeppe/go-internal v1.10.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
@@ -0,0 +1,254 @@
+package espresso
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/googollee/module"
+)
+
+var (
+	// HealthModule provides the config of health checks. `DefaultHealthConfig` is used if no config is provided.
+	HealthModule = module.New[*HealthConfig]()
+
+	DefaultHealthConfig = &HealthConfig{
+		Timeout: 5 * time.Second,
+	}
+)
+
+// HealthConfig configures health checks.
+type HealthConfig struct {
+	// Timeout is the max duration of each check. A check not returning in time fails. 0 means no limit.
+	Timeout time.Duration
+}
+
+// HealthChecker checks the health of a module.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// HealthCheckFunc is a function as a `HealthChecker`.
+type HealthCheckFunc func(ctx context.Context) error
+
+func (f HealthCheckFunc) CheckHealth(ctx context.Context) error {
+	return f(ctx)
+}
+
+// HealthCheckOption configures a health check.
+type HealthCheckOption func(*healthCheck)
+
+// Liveness makes the check also used by the liveness endpoint. By default, checks are only used by the readiness
+// endpoint.
+func Liveness() HealthCheckOption {
+	return func(c *healthCheck) {
+		c.liveness = true
+	}
+}
+
+type healthCheck struct {
+	name     string
+	checker  HealthChecker
+	liveness bool
+}
+
+type healthChecksKey struct{}
+
+type healthChecks struct {
+	mu     sync.Mutex
+	checks []healthCheck
+}
+
+func withHealthChecks(ctx context.Context, c *healthChecks) context.Context {
+	return context.WithValue(ctx, healthChecksKey{}, c)
+}
+
+// AddHealthCheck adds a check with `name` to health endpoints. It should be called in the function creating a module
+// instance, with the context of that function:
+//
+//	var ProvideDB = DBModule.ProvideWithFunc(func(ctx context.Context) (*sql.DB, error) {
+//	  db, err := sql.Open("postgres", dsn)
+//	  if err != nil {
+//	    return nil, err
+//	  }
+//	  espresso.AddHealthCheck(ctx, "db", espresso.HealthCheckFunc(db.PingContext))
+//	  return db, nil
+//	})
+func AddHealthCheck(ctx context.Context, name string, checker HealthChecker, opts ...HealthCheckOption) {
+	c, ok := ctx.Value(healthChecksKey{}).(*healthChecks)
+	if !ok {
+		return
+	}
+
+	check := healthCheck{
+		name:    name,
+		checker: checker,
+	}
+	for _, opt := range opts {
+		opt(&check)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, check)
+}
+
+func (c *healthChecks) list(liveness bool) []healthCheck {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var ret []healthCheck
+	for _, check := range c.checks {
+		if !liveness || check.liveness {
+			ret = append(ret, check)
+		}
+	}
+
+	// Modules are created in random order, so sort checks for stable reports.
+	slices.SortStableFunc(ret, func(a, b healthCheck) int {
+		return strings.Compare(a.name, b.name)
+	})
+	return ret
+}
+
+// Statuses of health reports and checks.
+const (
+	HealthPass = "pass"
+	HealthFail = "fail"
+)
+
+// HealthReport is the response of health endpoints.
+type HealthReport struct {
+	XMLName xml.Name            `json:"-" yaml:"-" xml:"health"`
+	Status  string              `json:"status" yaml:"status" xml:"status"`
+	Error   string              `json:"error,omitempty" yaml:"error,omitempty" xml:"error,omitempty"`
+	Checks  []HealthCheckResult `json:"checks,omitempty" yaml:"checks,omitempty" xml:"check,omitempty"`
+}
+
+// HealthCheckResult is the result of a check in `HealthReport`.
+type HealthCheckResult struct {
+	Name   string `json:"name" yaml:"name" xml:"name"`
+	Status string `json:"status" yaml:"status" xml:"status"`
+	Error  string `json:"error,omitempty" yaml:"error,omitempty" xml:"error,omitempty"`
+}
+
+// Health is the service of health endpoints, registered by `HandleAll`:
+//   - `GET /healthz` for liveness, with checks added with `Liveness()`.
+//   - `GET /readyz` for readiness, with all checks. It fails when the server is shutting down.
+//
+// Both endpoints respond HTTP 200 if all checks pass, or HTTP 503 otherwise.
+type Health struct {
+	server *Espresso
+}
+
+// Health returns the service of health endpoints:
+//
+//	svr.HandleAll(svr.Health())
+func (s *Espresso) Health() *Health {
+	return &Health{server: s}
+}
+
+// Liveness handles `GET /healthz`.
+func (h *Health) Liveness(ctx Context) error {
+	if err := ctx.Endpoint(http.MethodGet, "/healthz").End(); err != nil {
+		return err
+	}
+
+	return h.respond(ctx, h.check(ctx, h.server.health.list(true)))
+}
+
+// Readiness handles `GET /readyz`.
+func (h *Health) Readiness(ctx Context) error {
+	if err := ctx.Endpoint(http.MethodGet, "/readyz").End(); err != nil {
+		return err
+	}
+
+	if h.server.shuttingDown.Load() {
+		return h.respond(ctx, HealthReport{
+			Status: HealthFail,
+			Error:  "shutting down",
+		})
+	}
+
+	return h.respond(ctx, h.check(ctx, h.server.health.list(false)))
+}
+
+func (h *Health) check(ctx Context, checks []healthCheck) HealthReport {
+	cfg := HealthModule.Value(ctx)
+	if cfg == nil {
+		cfg = DefaultHealthConfig
+	}
+
+	ret := HealthReport{
+		Status: HealthPass,
+		Checks: make([]HealthCheckResult, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result := HealthCheckResult{
+				Name:   check.name,
+				Status: HealthPass,
+			}
+			if err := runCheck(ctx, cfg.Timeout, check.checker); err != nil {
+				result.Status = HealthFail
+				result.Error = err.Error()
+			}
+			ret.Checks[i] = result
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range ret.Checks {
+		if result.Status != HealthPass {
+			ret.Status = HealthFail
+		}
+	}
+
+	return ret
+}
+
+// runCheck runs the check with the timeout, and returns in time even if the check doesn't respect the context.
+func runCheck(ctx context.Context, timeout time.Duration, checker HealthChecker) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- checker.CheckHealth(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var healthCodecs = NewCodecs(JSON{}, YAML{})
+
+func (h *Health) respond(ctx Context, report HealthReport) error {
+	code := http.StatusOK
+	if report.Status != HealthPass {
+		code = http.StatusServiceUnavailable
+	}
+
+	codecs := CodecsModule.Value(ctx)
+	if codecs == nil {
+		codecs = healthCodecs
+	}
+
+	return codecs.EncodeResponseWithStatus(ctx, code, &report)
+}
@@ -0,0 +1,165 @@
+package espresso_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/googollee/module"
+
+	"github.com/googollee/go-espresso"
+)
+
+func TestHealth(t *testing.T) {
+	type DB struct{}
+	type Cache struct{}
+	dbModule := module.New[*DB]()
+	cacheModule := module.New[*Cache]()
+
+	var cacheErr error
+	var slow atomic.Bool
+	espo := espresso.New()
+	espo.AddModule(espresso.ProvideCodecs)
+	espo.AddModule(espresso.HealthModule.ProvideValue(&espresso.HealthConfig{Timeout: 10 * time.Millisecond}))
+	espo.AddModule(dbModule.ProvideWithFunc(func(ctx context.Context) (*DB, error) {
+		espresso.AddHealthCheck(ctx, "db", espresso.HealthCheckFunc(func(context.Context) error {
+			return nil
+		}), espresso.Liveness())
+		return &DB{}, nil
+	}))
+	espo.AddModule(cacheModule.ProvideWithFunc(func(ctx context.Context) (*Cache, error) {
+		espresso.AddHealthCheck(ctx, "cache", espresso.HealthCheckFunc(func(ctx context.Context) error {
+			return cacheErr
+		}))
+		espresso.AddHealthCheck(ctx, "slow", espresso.HealthCheckFunc(func(ctx context.Context) error {
+			if slow.Load() {
+				time.Sleep(time.Second)
+			}
+			return nil
+		}))
+		return &Cache{}, nil
+	}))
+	espo.HandleAll(espo.Health())
+
+	if err := espo.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		accept   string
+		cacheErr error
+		slow     bool
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "Liveness",
+			path:     "/healthz",
+			cacheErr: errors.New("cache is down"),
+			wantCode: http.StatusOK,
+			wantBody: `{"status":"pass","checks":[{"name":"db","status":"pass"}]}` + "\n",
+		},
+		{
+			name:     "Ready",
+			path:     "/readyz",
+			wantCode: http.StatusOK,
+			wantBody: `{"status":"pass","checks":[{"name":"cache","status":"pass"},{"name":"db","status":"pass"},{"name":"slow","status":"pass"}]}` + "\n",
+		},
+		{
+			name:     "NotReady",
+			path:     "/readyz",
+			cacheErr: errors.New("cache is down"),
+			slow:     true,
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: `{"status":"fail","checks":[{"name":"cache","status":"fail","error":"cache is down"},{"name":"db","status":"pass"},{"name":"slow","status":"fail","error":"context deadline exceeded"}]}` + "\n",
+		},
+		{
+			name:     "YAML",
+			path:     "/healthz",
+			accept:   "application/yaml",
+			wantCode: http.StatusOK,
+			wantBody: "status: pass\nchecks:\n    - name: db\n      status: pass\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cacheErr = tc.cacheErr
+			slow.Store(tc.slow)
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			resp := httptest.NewRecorder()
+			espo.ServeHTTP(resp, req)
+
+			if got, want := resp.Code, tc.wantCode; got != want {
+				t.Errorf("resp.Code = %d, want: %d", got, want)
+			}
+			if got, want := resp.Body.String(), tc.wantBody; got != want {
+				t.Errorf("resp.Body = %q, want: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestHealthShuttingDown(t *testing.T) {
+	espo := espresso.New()
+	espo.HandleAll(espo.Health())
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := "http://" + l.Addr().String() + "/readyz"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- espo.Serve(ctx, l, espresso.ShutdownDelay(time.Second))
+	}()
+
+	get := func() (int, string) {
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, string(body)
+	}
+
+	if code, body := get(); code != http.StatusOK {
+		t.Fatalf("readyz = %d %q before shutting down, want: 200", code, body)
+	}
+
+	cancel()
+	time.Sleep(100 * time.Millisecond)
+
+	code, body := get()
+	if got, want := code, http.StatusServiceUnavailable; got != want {
+		t.Errorf("resp.StatusCode = %d, want: %d", got, want)
+	}
+	if got, want := body, `{"status":"fail","error":"shutting down"}`+"\n"; got != want {
+		t.Errorf("resp.Body = %q, want: %q", got, want)
+	}
+
+	if err := <-serveErr; err != nil {
+		t.Fatalf("Serve() error: %v", err)
+	}
+}
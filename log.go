@@ -1,6 +1,15 @@
 package espresso
 
 import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/googollee/module"
 	"github.com/googollee/module/log"
 )
 
@@ -15,19 +24,190 @@ var (
 	ERROR = log.ERROR
 )
 
+var (
+	// AccessLogModule provides the config of access logs. `DefaultAccessLogConfig` is used if no config is provided.
+	AccessLogModule = module.New[*AccessLogConfig]()
+
+	DefaultAccessLogConfig = &AccessLogConfig{}
+)
+
+// AccessLogConfig configures access logs.
+type AccessLogConfig struct {
+	// TrustedProxies are networks of proxies trusted to set `X-Forwarded-For`. Without trusted proxies, the remote IP
+	// is the address of the connection.
+	TrustedProxies []netip.Prefix
+	// Fields are names of logging fields. Empty names use default names.
+	Fields AccessLogFields
+}
+
+// AccessLogFields are names of fields in access logs. A name "-" omits the field.
+type AccessLogFields struct {
+	Method    string // default "method"
+	Path      string // default "path"
+	Route     string // default "route"
+	Status    string // default "status"
+	Bytes     string // default "bytes"
+	Duration  string // default "duration"
+	RemoteIP  string // default "remote_ip"
+	UserAgent string // default "user_agent"
+	Error     string // default "error"
+}
+
+var defaultAccessLogFields = AccessLogFields{
+	Method:    "method",
+	Path:      "path",
+	Route:     "route",
+	Status:    "status",
+	Bytes:     "bytes",
+	Duration:  "duration",
+	RemoteIP:  "remote_ip",
+	UserAgent: "user_agent",
+	Error:     "error",
+}
+
+func (f AccessLogFields) withDefault() AccessLogFields {
+	for _, field := range []struct {
+		name *string
+		def  string
+	}{
+		{&f.Method, defaultAccessLogFields.Method},
+		{&f.Path, defaultAccessLogFields.Path},
+		{&f.Route, defaultAccessLogFields.Route},
+		{&f.Status, defaultAccessLogFields.Status},
+		{&f.Bytes, defaultAccessLogFields.Bytes},
+		{&f.Duration, defaultAccessLogFields.Duration},
+		{&f.RemoteIP, defaultAccessLogFields.RemoteIP},
+		{&f.UserAgent, defaultAccessLogFields.UserAgent},
+		{&f.Error, defaultAccessLogFields.Error},
+	} {
+		if *field.name == "" {
+			*field.name = field.def
+		}
+	}
+	return f
+}
+
+// attrs appends `key`/`value` pairs to `args`, omitting keys with "-".
+func attrs(args []any, kvs ...any) []any {
+	for i := 0; i+1 < len(kvs); i += 2 {
+		if kvs[i] == "-" {
+			continue
+		}
+		args = append(args, kvs[i], kvs[i+1])
+	}
+	return args
+}
+
 func logHandling(ctx Context) error {
-	method := ctx.Request().Method
-	path := ctx.Request().URL.String()
+	start := time.Now()
+
+	if LogModule.Value(ctx) == nil {
+		ctx.Next()
+		return nil
+	}
+
+	cfg := AccessLogModule.Value(ctx)
+	if cfg == nil {
+		cfg = DefaultAccessLogConfig
+	}
+	fields := cfg.Fields.withDefault()
 
-	slog := LogModule.Value(ctx)
-	if slog != nil {
-		ctx = ctx.WithParent(log.With(ctx, "method", method, "path", path))
+	r := ctx.Request()
+	ctx = ctx.WithParent(log.With(ctx, attrs(nil, fields.Method, r.Method, fields.Path, r.URL.String())...))
+
+	wr := &responseWriter{
+		ResponseWriter: ctx.ResponseWriter(),
 	}
+	ctx = ctx.WithResponseWriter(wr)
 
 	INFO(ctx, "receive http")
-	defer INFO(ctx, "finish http")
 
 	ctx.Next()
 
+	var route string
+	if endpoint := EndpointOf(ctx); endpoint != nil {
+		route = endpoint.Path
+	}
+
+	args := attrs(nil,
+		fields.Route, route,
+		fields.Status, wr.Status(),
+		fields.Bytes, wr.bytes,
+		fields.Duration, time.Since(start),
+		fields.RemoteIP, remoteIP(r, cfg.TrustedProxies),
+		fields.UserAgent, r.UserAgent(),
+	)
+	if wr.err != nil {
+		args = attrs(args, fields.Error, wr.err.Error())
+	}
+
+	LogModule.Value(ctx).Log(ctx, statusLevel(wr.Status()), "finish http", args...)
+
+	return nil
+}
+
+// statusLevel returns the log level of a status code: ERROR for 5xx, WARN for 4xx, and INFO for others.
+func statusLevel(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
+
+// remoteIP returns the IP of the client. If the connection is from a trusted proxy, it walks `X-Forwarded-For`
+// from the right, and returns the first address not from a trusted proxy.
+func remoteIP(r *http.Request, trusted []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	ip, err := netip.ParseAddr(host)
+	if err != nil || !isTrusted(ip, trusted) {
+		return host
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(forwarded[i])
+		if addr == "" {
+			continue
+		}
+
+		ip, err := netip.ParseAddr(addr)
+		if err != nil {
+			return host
+		}
+		host = ip.String()
+
+		if !isTrusted(ip, trusted) {
+			break
+		}
+	}
+
+	return host
+}
+
+func isTrusted(ip netip.Addr, trusted []netip.Prefix) bool {
+	ip = ip.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// EndpointOf returns the endpoint handling the request in `ctx`, or nil if `ctx` is not from `espresso`.
+func EndpointOf(ctx context.Context) *Endpoint {
+	switch ctx := ctx.(type) {
+	case *runtimeContext:
+		return ctx.endpoint
+	case *buildtimeContext:
+		return ctx.endpoint
+	}
 	return nil
 }
@@ -0,0 +1,120 @@
+package espresso_test
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/googollee/go-espresso"
+)
+
+func TestAccessLog(t *testing.T) {
+	removeDuration := func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.TimeKey || a.Key == "duration" || a.Key == "elapsed" {
+			return slog.Attr{}
+		}
+		return a
+	}
+
+	tests := []struct {
+		name       string
+		config     *espresso.AccessLogConfig
+		path       string
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{
+			name:       "OK",
+			path:       "/books/1?fields=title",
+			remoteAddr: "192.0.2.1:1234",
+			forwarded:  "203.0.113.1",
+			want:       `level=INFO msg="finish http" method=GET path="/books/1?fields=title" route=/books/{id} status=200 bytes=2 remote_ip=192.0.2.1 user_agent=test`,
+		},
+		{
+			name:       "NotFound",
+			path:       "/books/0",
+			remoteAddr: "192.0.2.1:1234",
+			want:       `level=WARN msg="finish http" method=GET path=/books/0 route=/books/{id} status=404 bytes=24 remote_ip=192.0.2.1 user_agent=test error="not found"`,
+		},
+		{
+			name:       "InternalError",
+			path:       "/books/2",
+			remoteAddr: "192.0.2.1:1234",
+			want:       `level=ERROR msg="finish http" method=GET path=/books/2 route=/books/{id} status=500 bytes=21 remote_ip=192.0.2.1 user_agent=test error=failed`,
+		},
+		{
+			name: "TrustedProxy",
+			config: &espresso.AccessLogConfig{
+				TrustedProxies: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+			},
+			path:       "/books/1",
+			remoteAddr: "10.0.0.2:1234",
+			forwarded:  "198.51.100.1, 203.0.113.1, 10.0.0.1",
+			want:       `level=INFO msg="finish http" method=GET path=/books/1 route=/books/{id} status=200 bytes=2 remote_ip=203.0.113.1 user_agent=test`,
+		},
+		{
+			name: "CustomFields",
+			config: &espresso.AccessLogConfig{
+				Fields: espresso.AccessLogFields{
+					Path:      "url",
+					Duration:  "elapsed",
+					UserAgent: "-",
+					RemoteIP:  "-",
+				},
+			},
+			path:       "/books/1",
+			remoteAddr: "192.0.2.1:1234",
+			want:       `level=INFO msg="finish http" method=GET url=/books/1 route=/books/{id} status=200 bytes=2`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var logs strings.Builder
+			logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{ReplaceAttr: removeDuration}))
+
+			espo := espresso.New()
+			espo.AddModule(espresso.LogModule.ProvideValue(logger))
+			if tc.config != nil {
+				espo.AddModule(espresso.AccessLogModule.ProvideValue(tc.config))
+			}
+			espo.AddModule(espresso.ProvideCodecs)
+			espo.HandleFunc(func(ctx espresso.Context) error {
+				var id int
+				if err := ctx.Endpoint(http.MethodGet, "/books/{id}").
+					BindPath("id", &id).
+					End(); err != nil {
+					return err
+				}
+
+				switch id {
+				case 0:
+					return espresso.Error(http.StatusNotFound, errors.New("not found"))
+				case 2:
+					return errors.New("failed")
+				}
+
+				_, err := ctx.ResponseWriter().Write([]byte("ok"))
+				return err
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.RemoteAddr = tc.remoteAddr
+			req.Header.Set("User-Agent", "test")
+			if tc.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tc.forwarded)
+			}
+			espo.ServeHTTP(httptest.NewRecorder(), req)
+
+			lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+			if got, want := lines[len(lines)-1], tc.want; got != want {
+				t.Errorf("log = %q, want: %q", got, want)
+			}
+		})
+	}
+}
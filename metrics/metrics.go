@@ -0,0 +1,96 @@
+// Package metrics collects RED metrics of espresso servers and exposes them in the Prometheus text format.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/googollee/module"
+
+	"github.com/googollee/go-espresso"
+)
+
+var (
+	// RegistryModule provides the registry to collect metrics. `DefaultRegistry` is used if no registry is provided.
+	RegistryModule = module.New[*Registry]()
+
+	DefaultRegistry = NewRegistry(nil)
+)
+
+// Middleware is a middleware collecting metrics of requests to the registry from `RegistryModule`. Metrics are labelled
+// by the method, the route pattern of the endpoint and the status class, like `2xx`.
+//
+// The status and the response size are read by `espresso.ResponseStatus()` and `espresso.ResponseBytes()`. Error bodies
+// written by `espresso` after handlers return are not counted.
+func Middleware(ctx espresso.Context) (ret error) {
+	reg := RegistryModule.Value(ctx)
+	if reg == nil {
+		reg = DefaultRegistry
+	}
+
+	method := ctx.Request().Method
+	route := unknownRoute
+	if endpoint := espresso.EndpointOf(ctx); endpoint != nil {
+		route = endpoint.Path
+	}
+
+	start := time.Now()
+	inFlight := reg.inFlight.with(labelValues{method, route})
+	inFlight.add(1)
+
+	defer func() {
+		inFlight.add(-1)
+
+		perr := recover()
+		status := espresso.ResponseStatus(ctx)
+		if perr != nil {
+			status = http.StatusInternalServerError
+		} else if status == 0 {
+			status = espresso.StatusOf(ret)
+		}
+		labels := labelValues{method, route, statusClass(status)}
+
+		reg.requests.with(labels).add(1)
+		reg.duration.with(labels).observe(time.Since(start).Seconds())
+		reg.responseSize.with(labels).observe(float64(espresso.ResponseBytes(ctx)))
+		reg.observeError(method, route, ret)
+
+		if perr != nil {
+			panic(perr)
+		}
+	}()
+
+	ctx.Next()
+
+	return ctx.Err()
+}
+
+const unknownRoute = "unknown"
+
+func (r *Registry) observeError(method, route string, err error) {
+	if err == nil {
+		return
+	}
+
+	var decodeErr espresso.DecodeError
+	if errors.As(err, &decodeErr) {
+		r.decodeErrors.with(labelValues{method, route}).add(1)
+		return
+	}
+
+	var bindErrs espresso.BindErrors
+	if errors.As(err, &bindErrs) {
+		for _, bindErr := range bindErrs {
+			r.bindErrors.with(labelValues{method, route, bindErr.From.String()}).add(1)
+		}
+	}
+}
+
+func statusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "unknown"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
@@ -0,0 +1,144 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/googollee/go-espresso"
+	"github.com/googollee/go-espresso/metrics"
+)
+
+func TestMiddleware(t *testing.T) {
+	type Book struct {
+		Title string `json:"title"`
+	}
+
+	reg := metrics.NewRegistry(&metrics.Config{
+		SizeBuckets: []float64{10, 100},
+	})
+
+	espo := espresso.New()
+	espo.AddModule(espresso.ProvideCodecs)
+	espo.AddModule(metrics.RegistryModule.ProvideValue(reg))
+	espo.Use(metrics.Middleware)
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		var id int
+		if err := ctx.Endpoint(http.MethodGet, "/books/{id}").
+			BindPath("id", &id).
+			End(); err != nil {
+			return err
+		}
+
+		_, _ = ctx.ResponseWriter().Write([]byte("espresso"))
+		return nil
+	})
+	espo.HandleFunc(espresso.RPC(func(ctx espresso.Context, book Book) (Book, error) {
+		if err := ctx.Endpoint(http.MethodPost, "/books").End(); err != nil {
+			return Book{}, err
+		}
+		return book, nil
+	}))
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	for _, req := range []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodGet, "/books/1", ""},
+		{http.MethodGet, "/books/2", ""},
+		{http.MethodGet, "/books/abc", ""},
+		{http.MethodPost, "/books", `{"title":"espresso"}`},
+		{http.MethodPost, "/books", `{"title":`},
+	} {
+		r, err := http.NewRequest(req.method, svr.URL+req.path, strings.NewReader(req.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	resp := httptest.NewRecorder()
+	reg.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if got, want := resp.Header().Get("Content-Type"), "text/plain; version=0.0.4; charset=utf-8"; got != want {
+		t.Errorf("Content-Type = %q, want: %q", got, want)
+	}
+
+	body := resp.Body.String()
+	for _, want := range []string{
+		"# TYPE espresso_http_requests_total counter\n",
+		`espresso_http_requests_total{method="GET",route="/books/{id}",status="2xx"} 2` + "\n",
+		`espresso_http_requests_total{method="GET",route="/books/{id}",status="4xx"} 1` + "\n",
+		`espresso_http_requests_total{method="POST",route="/books",status="2xx"} 1` + "\n",
+		`espresso_http_requests_total{method="POST",route="/books",status="4xx"} 1` + "\n",
+		"# TYPE espresso_http_request_duration_seconds histogram\n",
+		`espresso_http_request_duration_seconds_count{method="GET",route="/books/{id}",status="2xx"} 2` + "\n",
+		`espresso_http_response_size_bytes_bucket{method="GET",route="/books/{id}",status="2xx",le="10"} 2` + "\n",
+		`espresso_http_response_size_bytes_bucket{method="POST",route="/books",status="2xx",le="10"} 0` + "\n",
+		`espresso_http_response_size_bytes_bucket{method="POST",route="/books",status="2xx",le="100"} 1` + "\n",
+		`espresso_http_response_size_bytes_bucket{method="POST",route="/books",status="2xx",le="+Inf"} 1` + "\n",
+		`espresso_http_response_size_bytes_sum{method="GET",route="/books/{id}",status="2xx"} 16` + "\n",
+		`espresso_http_requests_in_flight{method="GET",route="/books/{id}"} 0` + "\n",
+		`espresso_bind_errors_total{method="GET",route="/books/{id}",source="path"} 1` + "\n",
+		`espresso_decode_errors_total{method="POST",route="/books"} 1` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics don't contain %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestMiddlewareConcurrent(t *testing.T) {
+	reg := metrics.NewRegistry(nil)
+
+	espo := espresso.New()
+	espo.AddModule(metrics.RegistryModule.ProvideValue(reg))
+	espo.Use(metrics.Middleware)
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		if err := ctx.Endpoint(http.MethodGet, "/books").End(); err != nil {
+			return err
+		}
+		return nil
+	})
+
+	const n = 100
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			espo.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/books", nil))
+		}()
+	}
+	wg.Wait()
+
+	var buf strings.Builder
+	if err := reg.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	body := buf.String()
+	for _, want := range []string{
+		`espresso_http_requests_total{method="GET",route="/books",status="2xx"} 100` + "\n",
+		`espresso_http_request_duration_seconds_bucket{method="GET",route="/books",status="2xx",le="+Inf"} 100` + "\n",
+		`espresso_http_requests_in_flight{method="GET",route="/books"} 0` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics don't contain %q, got:\n%s", want, body)
+		}
+	}
+}
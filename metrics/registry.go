@@ -0,0 +1,253 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Config configures a registry.
+type Config struct {
+	// Namespace is the prefix of metric names. Default "espresso".
+	Namespace string
+	// DurationBuckets are upper bounds of buckets of request durations, in seconds.
+	DurationBuckets []float64
+	// SizeBuckets are upper bounds of buckets of response sizes, in bytes.
+	SizeBuckets []float64
+}
+
+var (
+	DefaultDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+	DefaultSizeBuckets     = []float64{100, 1000, 10000, 100000, 1e6, 1e7}
+)
+
+// Registry collects metrics of requests. It's an `http.Handler` responding metrics in the Prometheus text exposition
+// format, to be mounted as `/metrics`.
+//
+// Collecting doesn't lock: series are looked up in `sync.Map`s and updated with atomic operations.
+type Registry struct {
+	families []*family
+
+	requests     *family
+	duration     *family
+	responseSize *family
+	inFlight     *family
+	bindErrors   *family
+	decodeErrors *family
+}
+
+// NewRegistry creates a registry with the config `cfg`. A nil config or empty fields use defaults.
+func NewRegistry(cfg *Config) *Registry {
+	var c Config
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.Namespace == "" {
+		c.Namespace = "espresso"
+	}
+	if len(c.DurationBuckets) == 0 {
+		c.DurationBuckets = DefaultDurationBuckets
+	}
+	if len(c.SizeBuckets) == 0 {
+		c.SizeBuckets = DefaultSizeBuckets
+	}
+
+	ret := &Registry{}
+	ret.requests = ret.newFamily(c.Namespace+"_http_requests_total", "counter",
+		"Total number of HTTP requests.", nil, "method", "route", "status")
+	ret.duration = ret.newFamily(c.Namespace+"_http_request_duration_seconds", "histogram",
+		"Duration of HTTP requests in seconds.", c.DurationBuckets, "method", "route", "status")
+	ret.responseSize = ret.newFamily(c.Namespace+"_http_response_size_bytes", "histogram",
+		"Size of HTTP responses in bytes.", c.SizeBuckets, "method", "route", "status")
+	ret.inFlight = ret.newFamily(c.Namespace+"_http_requests_in_flight", "gauge",
+		"Number of HTTP requests being handled.", nil, "method", "route")
+	ret.bindErrors = ret.newFamily(c.Namespace+"_bind_errors_total", "counter",
+		"Total number of errors binding request params.", nil, "method", "route", "source")
+	ret.decodeErrors = ret.newFamily(c.Namespace+"_decode_errors_total", "counter",
+		"Total number of errors decoding request bodies.", nil, "method", "route")
+
+	return ret
+}
+
+func (r *Registry) newFamily(name, typ, help string, buckets []float64, labels ...string) *family {
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	if len(labels) > maxLabels {
+		panic(fmt.Sprintf("metric %s has %d labels, more than %d", name, len(labels), maxLabels))
+	}
+
+	ret := &family{
+		name:    name,
+		typ:     typ,
+		help:    help,
+		labels:  labels,
+		buckets: buckets,
+	}
+	r.families = append(r.families, ret)
+	return ret
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = r.WriteText(w)
+}
+
+// WriteText writes all metrics to `w` in the Prometheus text exposition format.
+func (r *Registry) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range r.families {
+		f.write(bw)
+	}
+	return bw.Flush()
+}
+
+const maxLabels = 3
+
+// labelValues are values of labels of a series, as the key to look up the series without allocating.
+type labelValues [maxLabels]string
+
+type family struct {
+	name    string
+	typ     string
+	help    string
+	labels  []string
+	buckets []float64
+	series  sync.Map // labelValues -> *series
+}
+
+type series struct {
+	values labelValues
+	bounds []float64 // upper bounds of buckets of the family
+
+	value   atomicFloat // the value of counters and gauges, or the sum of histograms
+	count   atomic.Uint64
+	buckets []atomic.Uint64 // counts of observations in each bucket, not cumulative
+}
+
+func (f *family) with(values labelValues) *series {
+	if ret, ok := f.series.Load(values); ok {
+		return ret.(*series)
+	}
+
+	ret, _ := f.series.LoadOrStore(values, &series{
+		values:  values,
+		bounds:  f.buckets,
+		buckets: make([]atomic.Uint64, len(f.buckets)),
+	})
+	return ret.(*series)
+}
+
+func (s *series) add(v float64) {
+	s.value.add(v)
+}
+
+func (s *series) observe(v float64) {
+	s.value.add(v)
+	if i := sort.SearchFloat64s(s.bounds, v); i < len(s.buckets) {
+		s.buckets[i].Add(1)
+	}
+	s.count.Add(1)
+}
+
+// atomicFloat is a float64 updated atomically.
+type atomicFloat struct {
+	bits atomic.Uint64
+}
+
+func (f *atomicFloat) add(v float64) {
+	for {
+		old := f.bits.Load()
+		if f.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+func (f *atomicFloat) load() float64 {
+	return math.Float64frombits(f.bits.Load())
+}
+
+func (f *family) write(w *bufio.Writer) {
+	var list []*series
+	f.series.Range(func(_, v any) bool {
+		list = append(list, v.(*series))
+		return true
+	})
+	if len(list) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, f.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.typ)
+
+	slices.SortFunc(list, func(a, b *series) int {
+		return slices.Compare(a.values[:], b.values[:])
+	})
+
+	for _, s := range list {
+		values := s.values[:len(f.labels)]
+		if f.typ != "histogram" {
+			f.writeSample(w, "", values, "", s.value.load())
+			continue
+		}
+
+		var cumulative uint64
+		for i, upper := range f.buckets {
+			cumulative += s.buckets[i].Load()
+			f.writeSample(w, "_bucket", values, formatFloat(upper), float64(cumulative))
+		}
+		count := s.count.Load()
+		f.writeSample(w, "_bucket", values, "+Inf", float64(max(count, cumulative)))
+		f.writeSample(w, "_sum", values, "", s.value.load())
+		f.writeSample(w, "_count", values, "", float64(max(count, cumulative)))
+	}
+}
+
+func (f *family) writeSample(w *bufio.Writer, suffix string, values []string, le string, v float64) {
+	w.WriteString(f.name)
+	w.WriteString(suffix)
+	w.WriteByte('{')
+	for i, label := range f.labels {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		writeLabel(w, label, values[i])
+	}
+	if le != "" {
+		if len(f.labels) > 0 {
+			w.WriteByte(',')
+		}
+		writeLabel(w, "le", le)
+	}
+	w.WriteString("} ")
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func writeLabel(w *bufio.Writer, name, value string) {
+	w.WriteString(name)
+	w.WriteString(`="`)
+	labelEscaper.WriteString(w, value)
+	w.WriteByte('"')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
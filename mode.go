@@ -0,0 +1,28 @@
+package espresso
+
+import "github.com/googollee/module"
+
+// Mode describes how the server runs. The default mode is `ProductionMode`.
+type Mode int
+
+const (
+	// ProductionMode hides internal details, like panic messages and stacks, from responses.
+	ProductionMode Mode = iota
+	// DevelopmentMode includes internal details in responses, to help debugging.
+	DevelopmentMode
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ProductionMode:
+		return "production"
+	case DevelopmentMode:
+		return "development"
+	}
+	return "unknown"
+}
+
+var (
+	ModeModule             = module.New[Mode]()
+	ProvideDevelopmentMode = ModeModule.ProvideValue(DevelopmentMode)
+)
@@ -0,0 +1,261 @@
+package espresso
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/googollee/module"
+)
+
+var (
+	// MultipartModule provides the config to parse `multipart/form-data` requests.
+	// `DefaultMultipartConfig` is used if no config is provided.
+	MultipartModule = module.New[*MultipartConfig]()
+
+	// DefaultMultipartConfig stores up to 32MB of a multipart body in memory, with no other limits.
+	DefaultMultipartConfig = &MultipartConfig{
+		MaxMemory: 32 << 20,
+	}
+)
+
+// MultipartConfig configures parsing `multipart/form-data` requests.
+type MultipartConfig struct {
+	// MaxMemory is the max bytes of file parts stored in memory. Parts beyond it are stored in temporary files.
+	MaxMemory int64
+	// MaxBodySize is the max bytes of a multipart body, including parts stored in temporary files. 0 means no limit.
+	MaxBodySize int64
+	// MaxPartSize is the max bytes of a file part, if the bind param doesn't set `MaxSize()`. 0 means no limit.
+	MaxPartSize int64
+	// ContentTypes allows content types of file parts, if the bind param doesn't set `ContentTypes()`.
+	// A type could be a wildcard like `image/*`. Empty allows all.
+	ContentTypes []string
+}
+
+func multipartConfig(ctx context.Context) *MultipartConfig {
+	if cfg := MultipartModule.Value(ctx); cfg != nil {
+		return cfg
+	}
+	return DefaultMultipartConfig
+}
+
+var (
+	// ErrFileTooLarge is the error when a file part is larger than the limit.
+	ErrFileTooLarge = errors.New("file too large")
+	// ErrFileType is the error when the content type of a file part is not allowed.
+	ErrFileType = errors.New("file type not allowed")
+)
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+// MaxSize sets the max bytes of each file bound by `BindFile()`.
+func MaxSize(n int64) BindOption {
+	return func(p *BindParam) {
+		p.MaxSize = n
+	}
+}
+
+// ContentTypes sets allowed content types of each file bound by `BindFile()`. A type could be a wildcard like
+// `image/*`.
+func ContentTypes(types ...string) BindOption {
+	return func(p *BindParam) {
+		p.ContentTypes = types
+	}
+}
+
+func newFileParam(ret BindParam, v any) (BindParam, error) {
+	vt := reflect.TypeOf(v)
+	if vt == nil || vt.Kind() != reflect.Pointer || (vt.Elem() != fileHeaderType && vt.Elem() != fileHeadersType) {
+		return BindParam{}, fmt.Errorf("not support to bind %s key %q to %T, need *%s or *%s", ret.From, ret.Key, v, fileHeaderType, fileHeadersType)
+	}
+
+	ret.Type = vt.Elem()
+	return ret, nil
+}
+
+// parseMultipart parses a `multipart/form-data` body once, with limits in the config.
+func parseMultipart(ctx context.Context, r *http.Request) error {
+	if r.MultipartForm != nil {
+		return nil
+	}
+
+	cfg := multipartConfig(ctx)
+	if cfg.MaxBodySize > 0 {
+		r.Body = http.MaxBytesReader(nil, r.Body, cfg.MaxBodySize)
+	}
+
+	err := r.ParseMultipartForm(cfg.MaxMemory)
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return errBodyTooLarge(maxBytesErr.Limit)
+	}
+
+	return err
+}
+
+func isMultipart(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "multipart/form-data"
+}
+
+// bindFiles binds uploaded `files` of a param to `v`, with the size limit and allowed content types of the param.
+func bindFiles(binder BindParam, cfg *MultipartConfig, files []*multipart.FileHeader, v any) error {
+	if len(files) == 0 {
+		if binder.Required {
+			return ErrMissingParam
+		}
+		return nil
+	}
+
+	maxSize, types := binder.MaxSize, binder.ContentTypes
+	if maxSize == 0 {
+		maxSize = cfg.MaxPartSize
+	}
+	if len(types) == 0 {
+		types = cfg.ContentTypes
+	}
+
+	for i, file := range files {
+		err := checkFile(file.Size, file.Header.Get("Content-Type"), maxSize, types)
+		if err != nil && binder.Multi() {
+			err = BindIndexError{Index: i, Err: err}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	switch v := v.(type) {
+	case **multipart.FileHeader:
+		*v = files[0]
+	case *[]*multipart.FileHeader:
+		*v = files
+	}
+
+	return nil
+}
+
+func checkFile(size int64, contentType string, maxSize int64, types []string) error {
+	if maxSize > 0 && size > maxSize {
+		return fmt.Errorf("%w: %d bytes, limit %d bytes", ErrFileTooLarge, size, maxSize)
+	}
+
+	if len(types) > 0 && !matchContentType(contentType, types) {
+		return fmt.Errorf("%w: %q, allowed: %s", ErrFileType, contentType, strings.Join(types, ", "))
+	}
+
+	return nil
+}
+
+func matchContentType(contentType string, types []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, t := range types {
+		if t == "*/*" || t == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(t, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MultipartReader reads parts of a `multipart/form-data` request one by one, without buffering the whole body.
+type MultipartReader struct {
+	reader *multipart.Reader
+	cfg    *MultipartConfig
+}
+
+func newMultipartReader(ctx context.Context, r *http.Request) (*MultipartReader, error) {
+	cfg := multipartConfig(ctx)
+	if cfg.MaxBodySize > 0 {
+		r.Body = http.MaxBytesReader(nil, r.Body, cfg.MaxBodySize)
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	return &MultipartReader{
+		reader: reader,
+		cfg:    cfg,
+	}, nil
+}
+
+// Next returns the next part, or `io.EOF` if no more parts.
+// It returns a `BindError` with `ErrFileType` if the content type of a file part is not allowed by
+// `MultipartConfig.ContentTypes`, and the following call moves to the next part.
+func (r *MultipartReader) Next() (*MultipartPart, error) {
+	part, err := r.reader.NextPart()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &MultipartPart{
+		Part:  part,
+		limit: r.cfg.MaxPartSize,
+	}
+
+	if part.FileName() != "" {
+		if err := checkFile(0, part.Header.Get("Content-Type"), 0, r.cfg.ContentTypes); err != nil {
+			return nil, ret.error(err)
+		}
+	}
+
+	return ret, nil
+}
+
+// MultipartPart is a part of a `multipart/form-data` request.
+// Reading a file part beyond `MultipartConfig.MaxPartSize` returns a `BindError` with `ErrFileTooLarge`.
+type MultipartPart struct {
+	*multipart.Part
+	limit int64
+	read  int64
+}
+
+func (p *MultipartPart) Read(b []byte) (int, error) {
+	if p.limit <= 0 || p.FileName() == "" {
+		return p.Part.Read(b)
+	}
+
+	if p.read >= p.limit {
+		// Check whether the part ends exactly at the limit.
+		var one [1]byte
+		if n, err := p.Part.Read(one[:]); n == 0 && err != nil {
+			return 0, err
+		}
+		return 0, p.error(fmt.Errorf("%w: limit %d bytes", ErrFileTooLarge, p.limit))
+	}
+
+	if remain := p.limit - p.read; int64(len(b)) > remain {
+		b = b[:remain]
+	}
+
+	n, err := p.Part.Read(b)
+	p.read += int64(n)
+	return n, err
+}
+
+func (p *MultipartPart) error(err error) BindError {
+	return BindError{
+		Key:  p.FormName(),
+		From: BindFileParam,
+		Type: fileHeaderType,
+		Err:  err,
+	}
+}
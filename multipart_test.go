@@ -0,0 +1,390 @@
+package espresso_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/googollee/go-espresso"
+)
+
+type uploadFile struct {
+	field       string
+	name        string
+	contentType string
+	content     string
+}
+
+func newMultipartRequest(t *testing.T, url string, values map[string]string, files ...uploadFile) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for k, v := range values {
+		if err := w.WriteField(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range files {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, file.field, file.name))
+		header.Set("Content-Type", file.contentType)
+		part, err := w.CreatePart(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(part, file.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func TestBindFile(t *testing.T) {
+	type Attachments struct {
+		Files []*multipart.FileHeader `file:"attachment,maxsize=8"`
+	}
+
+	espo := espresso.New()
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		var name string
+		var avatar *multipart.FileHeader
+		var attachments Attachments
+		if err := ctx.Endpoint(http.MethodPost, "/profile").
+			BindForm("name", &name).
+			BindFile("avatar", &avatar, espresso.Required(), espresso.ContentTypes("image/*")).
+			BindStruct(&attachments).
+			End(); err != nil {
+			return espresso.Error(http.StatusBadRequest, err)
+		}
+
+		file, err := avatar.Open()
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		content, err := io.ReadAll(file)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(ctx.ResponseWriter(), "name=%s avatar=%s(%s) attachments=%d", name, avatar.Filename, content, len(attachments.Files))
+		return nil
+	})
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	tests := []struct {
+		name     string
+		files    []uploadFile
+		wantCode int
+		wantBody string
+	}{
+		{
+			name: "OK",
+			files: []uploadFile{
+				{"avatar", "me.png", "image/png", "png"},
+				{"attachment", "a.txt", "text/plain", "a"},
+				{"attachment", "b.txt", "text/plain", "b"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: "name=espresso avatar=me.png(png) attachments=2",
+		},
+		{
+			name:     "Missing",
+			wantCode: http.StatusBadRequest,
+			wantBody: `bind file with name "avatar" to type *multipart.FileHeader error: missing required value`,
+		},
+		{
+			name: "Invalid",
+			files: []uploadFile{
+				{"avatar", "me.txt", "text/plain", "txt"},
+				{"attachment", "a.txt", "text/plain", "a"},
+				{"attachment", "b.txt", "text/plain", "too large"},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `bind file with name "avatar" to type *multipart.FileHeader error: file type not allowed: "text/plain", allowed: image/*, ` +
+				`bind file with name "attachment" to type []*multipart.FileHeader error: element 1: file too large: 9 bytes, limit 8 bytes`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := newMultipartRequest(t, svr.URL+"/profile", map[string]string{"name": "espresso"}, tc.files...)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := resp.StatusCode, tc.wantCode; got != want {
+				t.Errorf("resp.StatusCode = %d, want: %d", got, want)
+			}
+			if got, want := string(body), tc.wantBody; got != want {
+				t.Errorf("resp.Body = %q, want: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestBindFileRemoveTempFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+
+	espo := espresso.New()
+	espo.AddModule(espresso.MultipartModule.ProvideValue(&espresso.MultipartConfig{
+		MaxMemory: 10,
+	}))
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		var file *multipart.FileHeader
+		if err := ctx.Endpoint(http.MethodPost, "/upload").
+			BindFile("file", &file, espresso.Required()).
+			End(); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(ctx.ResponseWriter(), "size=%d", file.Size)
+		return nil
+	})
+
+	req := newMultipartRequest(t, "/upload", nil, uploadFile{"file", "large.txt", "text/plain", strings.Repeat("x", 100<<10)})
+	resp := httptest.NewRecorder()
+	espo.ServeHTTP(resp, req)
+
+	if got, want := resp.Body.String(), fmt.Sprintf("size=%d", 100<<10); got != want {
+		t.Fatalf("resp.Body = %q, want: %q", got, want)
+	}
+
+	files, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("temporary files = %v, want: none", files)
+	}
+}
+
+func TestBindFormBodyLimit(t *testing.T) {
+	espo := espresso.New()
+	espo.AddModule(espresso.MultipartModule.ProvideValue(&espresso.MultipartConfig{
+		MaxBodySize: 256,
+	}))
+	espo.Use(espresso.DecodeWith(espresso.DecodeOptions{
+		MaxBodySize: 16,
+	}))
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		var name string
+		if err := ctx.Endpoint(http.MethodPost, "/profile").
+			BindForm("name", &name).
+			End(); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(ctx.ResponseWriter(), "name=%s", name)
+		return nil
+	})
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	urlEncoded := func(name string) *http.Request {
+		req, err := http.NewRequest(http.MethodPost, svr.URL+"/profile", strings.NewReader("name="+name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+
+	tests := []struct {
+		name     string
+		req      *http.Request
+		wantCode int
+	}{
+		{
+			name:     "URLEncoded",
+			req:      urlEncoded("espresso"),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "URLEncodedTooLarge",
+			req:      urlEncoded(strings.Repeat("espresso", 4)),
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "Multipart",
+			req:      newMultipartRequest(t, svr.URL+"/profile", map[string]string{"name": "espresso"}),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "MultipartTooLarge",
+			req:      newMultipartRequest(t, svr.URL+"/profile", map[string]string{"name": strings.Repeat("espresso", 32)}),
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.DefaultClient.Do(tc.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := resp.StatusCode, tc.wantCode; got != want {
+				t.Errorf("resp.StatusCode = %d, want: %d, body: %q", got, want, body)
+			}
+		})
+	}
+}
+
+func TestMultipartReader(t *testing.T) {
+	espo := espresso.New()
+	espo.AddModule(espresso.MultipartModule.ProvideValue(&espresso.MultipartConfig{
+		MaxPartSize:  4,
+		ContentTypes: []string{"text/plain"},
+	}))
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		if err := ctx.Endpoint(http.MethodPost, "/upload").End(); err != nil {
+			return err
+		}
+
+		reader, err := ctx.Multipart()
+		if err != nil {
+			return espresso.Error(http.StatusBadRequest, err)
+		}
+
+		for {
+			part, err := reader.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			var bindErr espresso.BindError
+			if errors.As(err, &bindErr) {
+				fmt.Fprintf(ctx.ResponseWriter(), "%s: %v;", bindErr.Key, bindErr.Err)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			content, err := io.ReadAll(part)
+			if errors.As(err, &bindErr) {
+				fmt.Fprintf(ctx.ResponseWriter(), "%s: %v;", bindErr.Key, bindErr.Err)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(ctx.ResponseWriter(), "%s=%s;", part.FormName(), content)
+		}
+
+		return nil
+	})
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	req := newMultipartRequest(t, svr.URL+"/upload", map[string]string{"name": "espresso"},
+		uploadFile{"a", "a.txt", "text/plain", "aaaa"},
+		uploadFile{"b", "b.txt", "text/plain", "bbbbb"},
+		uploadFile{"c", "c.png", "image/png", "c"},
+	)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `name=espresso;a=aaaa;b: file too large: limit 4 bytes;c: file type not allowed: "image/png", allowed: text/plain;`
+	if got := string(body); got != want {
+		t.Errorf("resp.Body = %q, want: %q", got, want)
+	}
+}
+
+func TestOpenAPIMultipart(t *testing.T) {
+	espo := espresso.New()
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		var name string
+		var avatar *multipart.FileHeader
+		var attachments []*multipart.FileHeader
+		if err := ctx.Endpoint(http.MethodPost, "/profile").
+			BindForm("name", &name).
+			BindFile("avatar", &avatar, espresso.Required(), espresso.ContentTypes("image/png", "image/jpeg")).
+			BindFile("attachment", &attachments).
+			End(); err != nil {
+			return err
+		}
+		return nil
+	})
+
+	body, err := json.Marshal(espo.OpenAPI().Paths["/profile"].Post.RequestBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got, want any
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{
+  "content": {
+    "multipart/form-data": {
+      "schema": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "avatar": {"type": "string", "contentMediaType": "application/octet-stream"},
+          "attachment": {"type": "array", "items": {"type": "string", "contentMediaType": "application/octet-stream"}}
+        },
+        "required": ["avatar"]
+      },
+      "encoding": {"avatar": {"contentType": "image/png, image/jpeg"}}
+    }
+  }
+}`), &want); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RequestBody = %s", strings.TrimSpace(string(body)))
+	}
+}
@@ -0,0 +1,522 @@
+package espresso
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/googollee/go-espresso/openapi"
+)
+
+// OpenAPI generates an OpenAPI 3.1 document of all registered endpoints.
+// It returns a new document for each call, so it's safe to change the returned document, like setting `Info`.
+// Media types of bodies are read from codecs of modules if the server has started, otherwise only JSON is used.
+func (s *Espresso) OpenAPI() *openapi.Document {
+	mimes := []string{JSON{}.Mime()}
+	if s.started.Load() && s.Start(context.Background()) == nil {
+		if codecs := CodecsModule.Value(s.modules); codecs != nil {
+			mimes = codecs.Mimes()
+		}
+	}
+
+	gen := newOpenAPIGenerator(mimes)
+	for _, e := range s.registry.endpoints {
+		gen.addEndpoint(e.name, e.endpoint)
+	}
+
+	return gen.document()
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	httpErrorType     = reflect.TypeOf(httpError{})
+
+	invalidSchemaName = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+	anonymousFunc     = regexp.MustCompile(`^func\d+$`)
+)
+
+type openAPIGenerator struct {
+	mimes        []string
+	paths        map[string]*openapi.PathItem
+	schemas      map[string]*openapi.Schema
+	names        map[reflect.Type]string
+	usedNames    map[string]bool
+	operationIDs map[string]bool
+}
+
+func newOpenAPIGenerator(mimes []string) *openAPIGenerator {
+	return &openAPIGenerator{
+		mimes:        mimes,
+		paths:        make(map[string]*openapi.PathItem),
+		schemas:      make(map[string]*openapi.Schema),
+		names:        map[reflect.Type]string{httpErrorType: "Error"},
+		usedNames:    map[string]bool{"Error": true},
+		operationIDs: make(map[string]bool),
+	}
+}
+
+func (g *openAPIGenerator) document() *openapi.Document {
+	ret := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:   "espresso",
+			Version: "0.0.0",
+		},
+		Paths: g.paths,
+	}
+
+	if schema, ok := g.schemas["Error"]; ok {
+		addErrorCatalog(schema)
+	}
+
+	if len(g.schemas) > 0 {
+		ret.Components = &openapi.Components{
+			Schemas: g.schemas,
+		}
+	}
+
+	return ret
+}
+
+// addErrorCatalog lists errors defined by `DefineError()` in the `code` property of the error schema.
+func addErrorCatalog(schema *openapi.Schema) {
+	code, ok := schema.Properties["code"]
+	catalog := ErrorCatalog()
+	if !ok || len(catalog) == 0 {
+		return
+	}
+
+	var desc strings.Builder
+	desc.WriteString("Error codes:\n")
+	for _, def := range catalog {
+		code.Enum = append(code.Enum, def.Code)
+		fmt.Fprintf(&desc, "\n- `%s` (%d): %s", def.Code, def.Status, def.Message)
+	}
+	code.Description = desc.String()
+}
+
+func (g *openAPIGenerator) addEndpoint(name string, endpoint *Endpoint) {
+	path := openAPIPath(endpoint.Path)
+
+	item, ok := g.paths[path]
+	if !ok {
+		item = &openapi.PathItem{}
+	}
+
+	op := item.Operation(endpoint.Method)
+	if op == nil {
+		return
+	}
+
+	*op = g.operation(name, endpoint)
+	g.paths[path] = item
+}
+
+// openAPIPath converts a pattern of `http.ServeMux` to an OpenAPI path, like `/files/{path...}` to `/files/{path}`.
+func openAPIPath(path string) string {
+	path = strings.ReplaceAll(path, "{$}", "")
+	path = strings.ReplaceAll(path, "...}", "}")
+	return path
+}
+
+func (g *openAPIGenerator) operation(name string, endpoint *Endpoint) *openapi.Operation {
+	ret := &openapi.Operation{
+		OperationID: g.operationID(name),
+		Responses:   make(map[string]*openapi.Response),
+	}
+
+	pathParams := sortedParams(endpoint.PathParams)
+	sort.SliceStable(pathParams, func(i, j int) bool {
+		return strings.Index(endpoint.Path, "{"+pathParams[i].Key) < strings.Index(endpoint.Path, "{"+pathParams[j].Key)
+	})
+	for _, params := range [][]BindParam{pathParams, sortedParams(endpoint.QueryParams), sortedParams(endpoint.HeadParams)} {
+		for _, param := range params {
+			ret.Parameters = append(ret.Parameters, g.parameter(param))
+		}
+	}
+
+	content := make(map[string]*openapi.MediaType)
+	if endpoint.RequestType != nil {
+		schema := g.schema(endpoint.RequestType)
+		for _, mime := range g.mimes {
+			content[mime] = &openapi.MediaType{Schema: schema}
+		}
+	}
+	if len(endpoint.FormParams) > 0 || len(endpoint.FileParams) > 0 {
+		media := &openapi.MediaType{
+			Schema: &openapi.Schema{
+				Type:       "object",
+				Properties: make(map[string]*openapi.Schema),
+			},
+		}
+		for _, param := range append(sortedParams(endpoint.FormParams), sortedParams(endpoint.FileParams)...) {
+			g.addFormParam(media, param)
+		}
+
+		// Files can only be uploaded with multipart bodies.
+		mime := "application/x-www-form-urlencoded"
+		if len(endpoint.FileParams) > 0 {
+			mime = "multipart/form-data"
+		}
+		content[mime] = media
+	}
+	if len(content) > 0 {
+		ret.RequestBody = &openapi.RequestBody{
+			Required: endpoint.RequestType != nil,
+			Content:  content,
+		}
+	}
+
+	ok := &openapi.Response{
+		Description: http.StatusText(http.StatusOK),
+	}
+	if endpoint.ResponseType != nil {
+		ok.Content = g.content(g.schema(endpoint.ResponseType))
+	}
+	ret.Responses[strconv.Itoa(http.StatusOK)] = ok
+
+	errSchema := g.schema(httpErrorType)
+	if len(ret.Parameters) > 0 || ret.RequestBody != nil {
+		ret.Responses[strconv.Itoa(http.StatusBadRequest)] = &openapi.Response{
+			Description: http.StatusText(http.StatusBadRequest),
+			Content:     g.content(errSchema),
+		}
+	}
+	ret.Responses["default"] = &openapi.Response{
+		Description: "Error",
+		Content:     g.content(errSchema),
+	}
+
+	return ret
+}
+
+func (g *openAPIGenerator) addFormParam(media *openapi.MediaType, param BindParam) {
+	schema := media.Schema
+
+	if param.Required {
+		schema.Required = append(schema.Required, param.Key)
+	}
+
+	if param.From != BindFileParam {
+		schema.Properties[param.Key] = g.paramSchema(param)
+		return
+	}
+
+	file := &openapi.Schema{
+		Type:             "string",
+		ContentMediaType: "application/octet-stream",
+	}
+	if param.Multi() {
+		file = &openapi.Schema{
+			Type:  "array",
+			Items: file,
+		}
+	}
+	schema.Properties[param.Key] = file
+
+	if len(param.ContentTypes) > 0 {
+		if media.Encoding == nil {
+			media.Encoding = make(map[string]*openapi.Encoding)
+		}
+		media.Encoding[param.Key] = &openapi.Encoding{
+			ContentType: strings.Join(param.ContentTypes, ", "),
+		}
+	}
+}
+
+func (g *openAPIGenerator) content(schema *openapi.Schema) map[string]*openapi.MediaType {
+	ret := make(map[string]*openapi.MediaType)
+	for _, mime := range g.mimes {
+		ret[mime] = &openapi.MediaType{Schema: schema}
+	}
+	return ret
+}
+
+// operationID returns the method or function name of a handler as the operation id.
+// It returns an empty string for anonymous functions or duplicated names.
+func (g *openAPIGenerator) operationID(name string) string {
+	name = strings.TrimSuffix(name, "-fm")
+	name = name[strings.LastIndex(name, ".")+1:]
+	if name == "" || anonymousFunc.MatchString(name) || strings.ContainsAny(name, "[]/") || g.operationIDs[name] {
+		return ""
+	}
+
+	g.operationIDs[name] = true
+	return name
+}
+
+func sortedParams(params map[string]BindParam) []BindParam {
+	ret := make([]BindParam, 0, len(params))
+	for _, param := range params {
+		ret = append(ret, param)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Key < ret[j].Key
+	})
+	return ret
+}
+
+func (g *openAPIGenerator) parameter(param BindParam) *openapi.Parameter {
+	ret := &openapi.Parameter{
+		Name:     param.Key,
+		Required: param.Required,
+		Schema:   g.paramSchema(param),
+	}
+
+	switch param.From {
+	case BindPathParam:
+		ret.In = "path"
+		// OpenAPI requires path params, even wildcards matching empty values.
+		ret.Required = true
+	case BindQueryParam:
+		ret.In = "query"
+	case BindHeadParam:
+		ret.In = "header"
+	}
+
+	if param.Multi() {
+		explode := false
+		switch {
+		case param.From != BindQueryParam:
+		case param.Split == SplitComma:
+			ret.Style = "form"
+			ret.Explode = &explode
+		case param.Split == SplitPipe:
+			ret.Style = "pipeDelimited"
+			ret.Explode = &explode
+		}
+	}
+
+	return ret
+}
+
+func (g *openAPIGenerator) paramSchema(param BindParam) *openapi.Schema {
+	var ret *openapi.Schema
+	if param.Multi() {
+		ret = &openapi.Schema{
+			Type:  "array",
+			Items: g.paramValueSchema(param.Type.Elem()),
+		}
+	} else {
+		ret = g.paramValueSchema(param.Type)
+	}
+
+	applyRules(ret, param.Rules)
+
+	if param.Default != nil {
+		ret.Default = schemaValue(ret, *param.Default)
+	}
+
+	return ret
+}
+
+// paramValueSchema returns the schema of a type binding from strings.
+// Types other than primitives are bound by `encoding.TextUnmarshaler` or registered functions, so they are strings.
+func (g *openAPIGenerator) paramValueSchema(t reflect.Type) *openapi.Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if _, ok := underlyingPointers[t.Kind()]; ok && t != durationType {
+		return g.schema(t)
+	}
+
+	if t == timeType {
+		return g.schema(t)
+	}
+
+	return &openapi.Schema{Type: "string"}
+}
+
+func (g *openAPIGenerator) schema(t reflect.Type) *openapi.Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &openapi.Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &openapi.Schema{}
+	case t.Name() == "":
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &openapi.Schema{Type: "string"}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return &openapi.Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &openapi.Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &openapi.Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &openapi.Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &openapi.Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &openapi.Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &openapi.Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &openapi.Schema{Type: "string", Format: "byte"}
+		}
+		return &openapi.Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &openapi.Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.namedSchema(t)
+	}
+
+	return &openapi.Schema{}
+}
+
+func (g *openAPIGenerator) namedSchema(t reflect.Type) *openapi.Schema {
+	name, ok := g.names[t]
+	if !ok {
+		name = invalidSchemaName.ReplaceAllString(t.Name(), "_")
+		if g.usedNames[name] {
+			name = invalidSchemaName.ReplaceAllString(t.String(), "_")
+		}
+		g.names[t] = name
+		g.usedNames[name] = true
+	}
+
+	if _, ok := g.schemas[name]; !ok {
+		g.schemas[name] = nil // Placeholder for recursive types.
+		g.schemas[name] = g.structSchema(t)
+	}
+
+	return openapi.RefTo(name)
+}
+
+func (g *openAPIGenerator) structSchema(t reflect.Type) *openapi.Schema {
+	ret := &openapi.Schema{
+		Type:       "object",
+		Properties: make(map[string]*openapi.Schema),
+	}
+	g.appendFields(ret, t)
+
+	return ret
+}
+
+func (g *openAPIGenerator) appendFields(ret *openapi.Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.appendFields(ret, ft)
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		schema := g.schema(f.Type)
+		if strings.Contains(","+opts+",", ",string,") {
+			schema = &openapi.Schema{Type: "string"}
+		}
+
+		if tag, ok := f.Tag.Lookup("validate"); ok {
+			// Tags are verified when registering handlers, so errors are ignored.
+			rules, _ := parseRules(tag)
+			applyRules(schema, rules)
+			for _, rule := range rules {
+				if rule.Name == "required" {
+					ret.Required = append(ret.Required, name)
+				}
+			}
+		}
+
+		ret.Properties[name] = schema
+	}
+}
+
+func applyRules(schema *openapi.Schema, rules []Rule) {
+	for _, rule := range rules {
+		target := schema
+		if rule.elem && schema.Type == "array" && schema.Items != nil {
+			target = schema.Items
+		}
+
+		switch rule.Name {
+		case "min":
+			n := rule.Arg.(float64)
+			target.Minimum = &n
+		case "max":
+			n := rule.Arg.(float64)
+			target.Maximum = &n
+		case "minLength":
+			n := rule.Arg.(int)
+			if target.Type == "array" {
+				target.MinItems = &n
+			} else {
+				target.MinLength = &n
+			}
+		case "maxLength":
+			n := rule.Arg.(int)
+			if target.Type == "array" {
+				target.MaxItems = &n
+			} else {
+				target.MaxLength = &n
+			}
+		case "pattern":
+			target.Pattern = rule.Arg.(string)
+		case "enum":
+			target.Enum = nil
+			for _, v := range rule.Arg.([]string) {
+				target.Enum = append(target.Enum, schemaValue(target, v))
+			}
+		}
+	}
+}
+
+// schemaValue converts a string value to the type of `schema`.
+func schemaValue(schema *openapi.Schema, v string) any {
+	switch schema.Type {
+	case "integer":
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return v
+}
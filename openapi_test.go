@@ -0,0 +1,195 @@
+package espresso_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/googollee/go-espresso"
+)
+
+type openAPIBook struct {
+	ID        int64     `json:"id"`
+	Title     string    `json:"title" validate:"required,maxlen=64"`
+	Tags      []string  `json:"tags,omitempty" validate:"enum=go|web"`
+	Published time.Time `json:"published"`
+	Author    *openAPIAuthor
+	internal  int
+}
+
+type openAPIAuthor struct {
+	Name  string         `json:"name"`
+	Books []*openAPIBook `json:"books"`
+}
+
+type openAPIService struct{}
+
+func (openAPIService) GetBook(ctx espresso.Context) error {
+	var id int64
+	var fields []string
+	var tenant string
+	if err := ctx.Endpoint(http.MethodGet, "/books/{id}").
+		BindPath("id", &id).
+		BindQuery("fields", &fields, espresso.Split(espresso.SplitComma)).
+		BindHead("X-Tenant", &tenant, espresso.Required()).
+		End(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func TestOpenAPI(t *testing.T) {
+	espo := espresso.New()
+	espo.AddModule(espresso.ProvideCodecs)
+	espo.WithPrefix("/v1").HandleAll(openAPIService{})
+	espo.WithPrefix("/v1").HandleFunc(espresso.RPC(func(ctx espresso.Context, book *openAPIBook) (*openAPIBook, error) {
+		var limit int
+		if err := ctx.Endpoint(http.MethodPost, "/books").
+			BindQuery("limit", &limit, espresso.Default("20"), espresso.Min(1)).
+			End(); err != nil {
+			return nil, err
+		}
+		return book, nil
+	}))
+
+	if err := espo.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	doc := espo.OpenAPI()
+
+	docJSON, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got, want any
+	if err := json.Unmarshal(docJSON, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(wantOpenAPI), &want); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.MarshalIndent(got, "", "  ")
+		wantJSON, _ := json.MarshalIndent(want, "", "  ")
+		t.Errorf("OpenAPI() = %s\nwant: %s", gotJSON, wantJSON)
+	}
+}
+
+const wantOpenAPI = `{
+  "openapi": "3.1.0",
+  "info": {"title": "espresso", "version": "0.0.0"},
+  "paths": {
+    "/v1/books": {
+      "post": {
+        "parameters": [
+          {"name": "limit", "in": "query", "schema": {"type": "integer", "format": "int64", "default": 20, "minimum": 1}}
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {"schema": {"$ref": "#/components/schemas/openAPIBook"}},
+            "application/yaml": {"schema": {"$ref": "#/components/schemas/openAPIBook"}}
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {"schema": {"$ref": "#/components/schemas/openAPIBook"}},
+              "application/yaml": {"schema": {"$ref": "#/components/schemas/openAPIBook"}}
+            }
+          },
+          "400": {
+            "description": "Bad Request",
+            "content": {
+              "application/json": {"schema": {"$ref": "#/components/schemas/Error"}},
+              "application/yaml": {"schema": {"$ref": "#/components/schemas/Error"}}
+            }
+          },
+          "default": {
+            "description": "Error",
+            "content": {
+              "application/json": {"schema": {"$ref": "#/components/schemas/Error"}},
+              "application/yaml": {"schema": {"$ref": "#/components/schemas/Error"}}
+            }
+          }
+        }
+      }
+    },
+    "/v1/books/{id}": {
+      "get": {
+        "operationId": "GetBook",
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "schema": {"type": "integer", "format": "int64"}},
+          {"name": "fields", "in": "query", "style": "form", "explode": false, "schema": {"type": "array", "items": {"type": "string"}}},
+          {"name": "X-Tenant", "in": "header", "required": true, "schema": {"type": "string"}}
+        ],
+        "responses": {
+          "200": {"description": "OK"},
+          "400": {
+            "description": "Bad Request",
+            "content": {
+              "application/json": {"schema": {"$ref": "#/components/schemas/Error"}},
+              "application/yaml": {"schema": {"$ref": "#/components/schemas/Error"}}
+            }
+          },
+          "default": {
+            "description": "Error",
+            "content": {
+              "application/json": {"schema": {"$ref": "#/components/schemas/Error"}},
+              "application/yaml": {"schema": {"$ref": "#/components/schemas/Error"}}
+            }
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Error": {
+        "type": "object",
+        "properties": {
+          "code": {
+            "type": "string",
+            "enum": ["BOOK_NOT_FOUND", "BOOK_OUT_OF_STOCK"],
+            "description": "Error codes:\n\n- ` + "`BOOK_NOT_FOUND`" + ` (404): book %d not found\n- ` + "`BOOK_OUT_OF_STOCK`" + ` (409): book %q is out of stock"
+          },
+          "message": {"type": "string"},
+          "errors": {"type": "array", "items": {"$ref": "#/components/schemas/ErrorDetail"}},
+          "request_id": {"type": "string"}
+        }
+      },
+      "ErrorDetail": {
+        "type": "object",
+        "properties": {
+          "field": {"type": "string"},
+          "source": {"type": "string"},
+          "message": {"type": "string"}
+        }
+      },
+      "openAPIAuthor": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "books": {"type": "array", "items": {"$ref": "#/components/schemas/openAPIBook"}}
+        }
+      },
+      "openAPIBook": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "integer", "format": "int64"},
+          "title": {"type": "string", "maxLength": 64},
+          "tags": {"type": "array", "items": {"type": "string", "enum": ["go", "web"]}},
+          "published": {"type": "string", "format": "date-time"},
+          "Author": {"$ref": "#/components/schemas/openAPIAuthor"}
+        },
+        "required": ["title"]
+      }
+    }
+  }
+}`
@@ -0,0 +1,123 @@
+// Package otel traces espresso servers with OpenTelemetry.
+package otel
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/googollee/module"
+	"github.com/googollee/module/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/googollee/go-espresso"
+)
+
+const tracerName = "github.com/googollee/go-espresso/otel"
+
+var (
+	// TracerProviderModule provides the tracer provider to start spans.
+	// The global provider of `otel.GetTracerProvider()` is used if no provider is provided.
+	TracerProviderModule = module.New[trace.TracerProvider]()
+	// PropagatorModule provides the propagator to extract trace contexts from requests.
+	// W3C `traceparent` and `baggage` are extracted if no propagator is provided.
+	PropagatorModule = module.New[propagation.TextMapPropagator]()
+)
+
+var defaultPropagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Tracing is a middleware starting a server span for each request, named after the method and the route pattern of
+// the endpoint, like `GET /books/{id}`. The span records the status code, errors returned by handlers, including
+// each bind error, and panics. Logs with the context have `trace_id` and `span_id`.
+func Tracing(ctx espresso.Context) (ret error) {
+	tp := TracerProviderModule.Value(ctx)
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	propagator := PropagatorModule.Value(ctx)
+	if propagator == nil {
+		propagator = defaultPropagator
+	}
+
+	r := ctx.Request()
+	name, route := r.Method, ""
+	if endpoint := espresso.EndpointOf(ctx); endpoint != nil {
+		route = endpoint.Path
+		name = r.Method + " " + route
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(r.Method),
+		semconv.URLPath(r.URL.Path),
+		semconv.UserAgentOriginal(r.UserAgent()),
+	}
+	if route != "" {
+		attrs = append(attrs, semconv.HTTPRoute(route))
+	}
+
+	spanCtx, span := tp.Tracer(tracerName).Start(
+		propagator.Extract(ctx, propagation.HeaderCarrier(r.Header)),
+		name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrs...),
+	)
+	defer span.End(trace.WithStackTrace(true))
+
+	sc := span.SpanContext()
+	spanCtx = log.With(spanCtx, "trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String())
+
+	ctx = ctx.WithParent(spanCtx)
+
+	defer func() {
+		if perr := recover(); perr != nil {
+			// The deferred span.End() records the panic as an exception event when panic again.
+			setStatus(span, http.StatusInternalServerError)
+			panic(perr)
+		}
+
+		status := espresso.ResponseStatus(ctx)
+		if ret != nil && status == 0 {
+			status = espresso.StatusOf(ret)
+		}
+		if status == 0 {
+			status = http.StatusOK
+		}
+		recordError(span, ret)
+		setStatus(span, status)
+	}()
+
+	ctx.Next()
+
+	return ctx.Err()
+}
+
+func recordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	var bindErrs espresso.BindErrors
+	if !errors.As(err, &bindErrs) {
+		span.RecordError(err)
+		return
+	}
+
+	for _, bindErr := range bindErrs {
+		span.RecordError(bindErr, trace.WithAttributes(
+			attribute.String("espresso.bind.key", bindErr.Key),
+			attribute.String("espresso.bind.source", bindErr.From.String()),
+		))
+	}
+}
+
+// setStatus sets the status code of a server span. Only 5xx responses are errors of server spans.
+func setStatus(span trace.Span, status int) {
+	span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+}
@@ -0,0 +1,154 @@
+package otel_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/googollee/go-espresso"
+	"github.com/googollee/go-espresso/otel"
+)
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+
+	var logs strings.Builder
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	espo := espresso.New()
+	espo.AddModule(espresso.LogModule.ProvideValue(logger))
+	espo.AddModule(otel.TracerProviderModule.ProvideValue(trace.TracerProvider(tp)))
+	espo.Use(otel.Tracing)
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		var id int
+		if err := ctx.Endpoint(http.MethodGet, "/books/{id}").
+			BindPath("id", &id).
+			End(); err != nil {
+			return err
+		}
+
+		if id == 0 {
+			panic("boom")
+		}
+
+		espresso.INFO(ctx, "handling")
+		return nil
+	})
+
+	tests := []struct {
+		name        string
+		path        string
+		traceparent string
+		wantStatus  int
+		wantCode    codes.Code
+		wantEvents  int
+		wantBindKey string
+	}{
+		{
+			name:        "OK",
+			path:        "/books/1",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantStatus:  http.StatusOK,
+			wantCode:    codes.Unset,
+		},
+		{
+			name:        "BindError",
+			path:        "/books/abc",
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    codes.Unset,
+			wantEvents:  1,
+			wantBindKey: "id",
+		},
+		{
+			name:       "Panic",
+			path:       "/books/0",
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   codes.Error,
+			wantEvents: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			exporter.Reset()
+			logs.Reset()
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.traceparent != "" {
+				req.Header.Set("traceparent", tc.traceparent)
+			}
+			resp := httptest.NewRecorder()
+			espo.ServeHTTP(resp, req)
+
+			if got, want := resp.Code, tc.wantStatus; got != want {
+				t.Errorf("resp.Code = %d, want: %d", got, want)
+			}
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("len(spans) = %d, want: 1", len(spans))
+			}
+			span := spans[0]
+
+			if got, want := span.Name, "GET /books/{id}"; got != want {
+				t.Errorf("span.Name = %q, want: %q", got, want)
+			}
+			if got, want := span.SpanKind, trace.SpanKindServer; got != want {
+				t.Errorf("span.SpanKind = %v, want: %v", got, want)
+			}
+			if got, want := span.Status.Code, tc.wantCode; got != want {
+				t.Errorf("span.Status.Code = %v, want: %v", got, want)
+			}
+			if got, want := attr(span.Attributes, "http.response.status_code"), attribute.IntValue(tc.wantStatus); got != want {
+				t.Errorf("http.response.status_code = %v, want: %v", got.Emit(), want.Emit())
+			}
+			if got, want := attr(span.Attributes, "http.route"), attribute.StringValue("/books/{id}"); got != want {
+				t.Errorf("http.route = %v, want: %v", got.Emit(), want.Emit())
+			}
+			if got, want := len(span.Events), tc.wantEvents; got != want {
+				t.Errorf("len(span.Events) = %d, want: %d", got, want)
+			}
+			if tc.wantBindKey != "" && len(span.Events) > 0 {
+				if got, want := attr(span.Events[0].Attributes, "espresso.bind.key"), attribute.StringValue(tc.wantBindKey); got != want {
+					t.Errorf("espresso.bind.key = %v, want: %v", got.Emit(), want.Emit())
+				}
+			}
+
+			if tc.traceparent != "" {
+				if got, want := span.Parent.TraceID().String(), "4bf92f3577b34da6a3ce929d0e0e4736"; got != want {
+					t.Errorf("span.Parent.TraceID() = %s, want: %s", got, want)
+				}
+				if got, want := span.SpanContext.TraceID().String(), "4bf92f3577b34da6a3ce929d0e0e4736"; got != want {
+					t.Errorf("span.TraceID() = %s, want: %s", got, want)
+				}
+			}
+
+			if tc.wantStatus == http.StatusOK {
+				want := "trace_id=" + span.SpanContext.TraceID().String() + " span_id=" + span.SpanContext.SpanID().String()
+				if !strings.Contains(logs.String(), want) {
+					t.Errorf("logs = %q, want containing: %q", logs.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func attr(attrs []attribute.KeyValue, key attribute.Key) attribute.Value {
+	for _, kv := range attrs {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
@@ -0,0 +1,175 @@
+package espresso
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ProblemDetails is an error responded as RFC 9457 problem details, with the media type like
+// `application/problem+json` or `application/problem+xml`, depending on the negotiated codec.
+type ProblemDetails struct {
+	Type     string
+	Title    string
+	Status   int
+	Detail   string
+	Instance string
+	// Errors lists invalid fields or params in the request.
+	Errors []ErrorDetail
+	// Extensions are extension members at the top level of the problem.
+	Extensions map[string]any
+
+	err error
+}
+
+// Problem returns a problem with the `status` code, describing `err`. Invalid fields or params in `err`, like
+// `BindErrors`, are listed in `Errors`.
+func Problem(status int, err error) *ProblemDetails {
+	ret := &ProblemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: err.Error(),
+		err:    err,
+	}
+
+	var httpErr *httpError
+	if errors.As(err, &httpErr) {
+		ret.Detail = httpErr.Message
+		if httpErr.Code != "" {
+			ret.Extensions = map[string]any{"code": httpErr.Code}
+		}
+	}
+
+	var details errorDetailer
+	if errors.As(err, &details) {
+		ret.Errors = details.errorDetails()
+	}
+
+	return ret
+}
+
+func (p *ProblemDetails) Error() string {
+	if p.err != nil {
+		return p.err.Error()
+	}
+	return fmt.Sprintf("%d %s: %s", p.Status, p.Title, p.Detail)
+}
+
+func (p *ProblemDetails) HTTPCode() int {
+	return p.Status
+}
+
+func (p *ProblemDetails) Unwrap() error {
+	return p.err
+}
+
+// members returns all members of the problem. Extensions don't override standard members.
+func (p *ProblemDetails) members() map[string]any {
+	ret := make(map[string]any, len(p.Extensions)+6)
+	for k, v := range p.Extensions {
+		ret[k] = v
+	}
+
+	for k, v := range map[string]any{
+		"type":     p.Type,
+		"title":    p.Title,
+		"detail":   p.Detail,
+		"instance": p.Instance,
+	} {
+		if v != "" {
+			ret[k] = v
+		} else {
+			delete(ret, k)
+		}
+	}
+	ret["status"] = p.Status
+	if len(p.Errors) > 0 {
+		ret["errors"] = p.Errors
+	} else {
+		delete(ret, "errors")
+	}
+
+	return ret
+}
+
+func (p *ProblemDetails) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.members())
+}
+
+func (p *ProblemDetails) MarshalYAML() (any, error) {
+	return p.members(), nil
+}
+
+// MarshalXML encodes the problem in the XML format of RFC 9457 appendix B.
+func (p *ProblemDetails) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{
+		Name: xml.Name{Space: "urn:ietf:rfc:7807", Local: "problem"},
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	members := p.members()
+	keys := make([]string, 0, len(members))
+	for k := range members {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := members[k]
+		if k == "errors" {
+			v = struct {
+				Errors []ErrorDetail `xml:"error"`
+			}{p.Errors}
+		}
+		if err := e.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: k}}); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// ProblemErrors is a middleware responding errors and panics of following handlers as `*ProblemDetails`.
+// Errors which are already `*ProblemDetails` are responded as is.
+func ProblemErrors(ctx Context) (ret error) {
+	defer func() {
+		if err := checkError(ctx, recover()); err != nil {
+			ret = toProblem(err)
+		}
+	}()
+
+	ctx.Next()
+
+	return nil
+}
+
+func toProblem(err error) *ProblemDetails {
+	var problem *ProblemDetails
+	if errors.As(err, &problem) {
+		return problem
+	}
+
+	code := http.StatusInternalServerError
+	if httpCoder, ok := err.(HTTPError); ok {
+		code = httpCoder.HTTPCode()
+	}
+
+	return Problem(code, err)
+}
+
+// problemMime returns the media type of problems encoded by a codec, like `application/problem+json` for
+// `application/json`.
+func problemMime(mime string) string {
+	_, subtype, ok := strings.Cut(mime, "/")
+	if !ok {
+		return mime
+	}
+	return "application/problem+" + subtype
+}
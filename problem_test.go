@@ -0,0 +1,117 @@
+package espresso_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/googollee/go-espresso"
+)
+
+func TestProblemErrors(t *testing.T) {
+	espo := espresso.New()
+	espo.AddModule(espresso.CodecsModule.ProvideValue(espresso.NewCodecs(espresso.JSON{}, espresso.YAML{}, espresso.XML{})))
+	espo.Use(espresso.ProblemErrors)
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		var id int
+		if err := ctx.Endpoint(http.MethodGet, "/books/{id}").
+			BindPath("id", &id).
+			End(); err != nil {
+			return err
+		}
+
+		switch id {
+		case 1:
+			return errors.New("internal")
+		case 2:
+			return &espresso.ProblemDetails{
+				Type:       "https://example.com/probs/out-of-stock",
+				Title:      "Out of stock",
+				Status:     http.StatusConflict,
+				Instance:   "/books/2",
+				Extensions: map[string]any{"stock": 0, "title": "ignored"},
+			}
+		}
+
+		return nil
+	})
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		accept   string
+		wantCode int
+		wantType string
+		wantBody string
+	}{
+		{
+			name:     "BindErrors",
+			path:     "/books/abc",
+			wantCode: http.StatusBadRequest,
+			wantType: "application/problem+json; charset=utf-8",
+			wantBody: `{"detail":"bind path with name \"id\" to type int error: strconv.ParseInt: parsing \"abc\": invalid syntax","errors":[{"field":"id","source":"path","message":"strconv.ParseInt: parsing \"abc\": invalid syntax"}],"status":400,"title":"Bad Request","type":"about:blank"}` + "\n",
+		},
+		{
+			name:     "Error",
+			path:     "/books/1",
+			accept:   "application/yaml",
+			wantCode: http.StatusInternalServerError,
+			wantType: "application/problem+yaml; charset=utf-8",
+			wantBody: "detail: internal\nstatus: 500\ntitle: Internal Server Error\ntype: about:blank\n",
+		},
+		{
+			name:     "Custom",
+			path:     "/books/2",
+			wantCode: http.StatusConflict,
+			wantType: "application/problem+json; charset=utf-8",
+			wantBody: `{"instance":"/books/2","status":409,"stock":0,"title":"Out of stock","type":"https://example.com/probs/out-of-stock"}` + "\n",
+		},
+		{
+			name:     "CustomXML",
+			path:     "/books/2",
+			accept:   "application/xml",
+			wantCode: http.StatusConflict,
+			wantType: "application/problem+xml; charset=utf-8",
+			wantBody: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<problem xmlns="urn:ietf:rfc:7807"><instance>/books/2</instance><status>409</status><stock>0</stock><title>Out of stock</title><type>https://example.com/probs/out-of-stock</type></problem>`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, svr.URL+tc.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := resp.StatusCode, tc.wantCode; got != want {
+				t.Errorf("resp.StatusCode = %d, want: %d", got, want)
+			}
+			if got, want := resp.Header.Get("Content-Type"), tc.wantType; got != want {
+				t.Errorf("resp.Header[Content-Type] = %q, want: %q", got, want)
+			}
+			if got, want := string(body), tc.wantBody; got != want {
+				t.Errorf("resp.Body = %q, want: %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package espresso
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"maps"
+
+	"github.com/googollee/module"
+	"github.com/googollee/module/log"
+)
+
+var (
+	// RequestIDModule provides the config of request IDs. Request IDs are enabled only if a config is provided.
+	RequestIDModule = module.New[*RequestIDConfig]()
+	// ProvideRequestID enables request IDs with the default config.
+	ProvideRequestID = RequestIDModule.ProvideValue(&RequestIDConfig{})
+)
+
+// RequestIDConfig configures request IDs.
+type RequestIDConfig struct {
+	// Header is the header to read and respond the request ID. The default is `X-Request-ID`.
+	Header string
+	// Generator generates an ID if the request has no valid ID. The default generates 16 random bytes in hex.
+	Generator func() string
+}
+
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestIDOf returns the ID of the request in `ctx`, or an empty string if request IDs are not enabled.
+func RequestIDOf(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// requestIDHandling reads the request ID from the header or generates one, and puts it into the context, logs and
+// the response header.
+func requestIDHandling(ctx Context) error {
+	cfg := RequestIDModule.Value(ctx)
+	if cfg == nil {
+		ctx.Next()
+		return nil
+	}
+
+	header := cfg.Header
+	if header == "" {
+		header = "X-Request-ID"
+	}
+
+	id := ctx.Request().Header.Get(header)
+	if !validRequestID(id) {
+		if cfg.Generator != nil {
+			id = cfg.Generator()
+		} else {
+			id = newRequestID()
+		}
+	}
+
+	ctx.ResponseWriter().Header().Set(header, id)
+
+	reqCtx := context.WithValue(ctx, requestIDKey{}, id)
+	ctx = ctx.WithParent(log.With(reqCtx, "request_id", id))
+	ctx.Next()
+
+	return nil
+}
+
+// validRequestID checks that an ID from clients is short and printable, to avoid injecting logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// withRequestID returns a copy of the error with the request ID, to respond in the body.
+func withRequestID(err error, id string) error {
+	if id == "" {
+		return err
+	}
+
+	var problem *ProblemDetails
+	if errors.As(err, &problem) {
+		ret := *problem
+		ret.Extensions = maps.Clone(problem.Extensions)
+		if ret.Extensions == nil {
+			ret.Extensions = make(map[string]any)
+		}
+		ret.Extensions["request_id"] = id
+		return &ret
+	}
+
+	if httpErr, ok := err.(*httpError); ok {
+		ret := *httpErr
+		ret.RequestID = id
+		return &ret
+	}
+
+	return err
+}
@@ -0,0 +1,106 @@
+package espresso_test
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/googollee/go-espresso"
+)
+
+func TestRequestID(t *testing.T) {
+	var logs strings.Builder
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "duration" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	espo := espresso.New()
+	espo.AddModule(espresso.LogModule.ProvideValue(logger))
+	espo.AddModule(espresso.ProvideCodecs)
+	espo.AddModule(espresso.RequestIDModule.ProvideValue(&espresso.RequestIDConfig{
+		Generator: func() string { return "generated" },
+	}))
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		var fail bool
+		if err := ctx.Endpoint(http.MethodGet, "/").
+			BindQuery("fail", &fail).
+			End(); err != nil {
+			return err
+		}
+
+		if fail {
+			return espresso.Error(http.StatusConflict, errors.New("conflict"))
+		}
+
+		_, err := io.WriteString(ctx.ResponseWriter(), espresso.RequestIDOf(ctx))
+		return err
+	})
+
+	tests := []struct {
+		name     string
+		query    string
+		id       string
+		wantID   string
+		wantBody string
+	}{
+		{
+			name:     "FromHeader",
+			id:       "client-id",
+			wantID:   "client-id",
+			wantBody: "client-id",
+		},
+		{
+			name:     "Generated",
+			wantID:   "generated",
+			wantBody: "generated",
+		},
+		{
+			name:     "InvalidHeader",
+			id:       "bad id\n",
+			wantID:   "generated",
+			wantBody: "generated",
+		},
+		{
+			name:     "InErrorBody",
+			query:    "?fail=true",
+			id:       "client-id",
+			wantID:   "client-id",
+			wantBody: `{"message":"conflict","request_id":"client-id"}` + "\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logs.Reset()
+
+			req := httptest.NewRequest(http.MethodGet, "/"+tc.query, nil)
+			if tc.id != "" {
+				req.Header.Set("X-Request-ID", tc.id)
+			}
+			resp := httptest.NewRecorder()
+			espo.ServeHTTP(resp, req)
+
+			if got, want := resp.Header().Get("X-Request-ID"), tc.wantID; got != want {
+				t.Errorf("resp.Header[X-Request-ID] = %q, want: %q", got, want)
+			}
+			if got, want := resp.Body.String(), tc.wantBody; got != want {
+				t.Errorf("resp.Body = %q, want: %q", got, want)
+			}
+
+			for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+				if !strings.Contains(line, "request_id="+tc.wantID) {
+					t.Errorf("log %q doesn't contain the request id %q", line, tc.wantID)
+				}
+			}
+		})
+	}
+}
@@ -2,17 +2,75 @@ package espresso
 
 import "net/http"
 
+// responseWriter tracks the status, written bytes and the handling error of a response.
 type responseWriter struct {
 	http.ResponseWriter
 	hasWritten bool
+	status     int
+	bytes      int64
+	err        error
 }
 
 func (w *responseWriter) Write(p []byte) (int, error) {
+	if !w.hasWritten {
+		w.status = http.StatusOK
+	}
 	w.hasWritten = true
-	return w.ResponseWriter.Write(p)
+
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
 }
 
 func (w *responseWriter) WriteHeader(code int) {
+	if !w.hasWritten {
+		w.status = code
+	}
 	w.hasWritten = true
 	w.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap returns the original writer, for `http.ResponseController`.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Status returns the status code of the response. It's 200 if nothing has been written, as `http.Server` does.
+func (w *responseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// ResponseStatus returns the status code written to the response of `ctx`, or 0 if nothing has been written yet.
+// Middlewares could read it after `ctx.Next()`, without wrapping the response writer. If a handler returns an error
+// without writing, the error is written after all middlewares return, with the code of `StatusOf()`.
+func ResponseStatus(ctx Context) int {
+	if wr := coreResponseWriter(ctx.ResponseWriter()); wr != nil {
+		return wr.status
+	}
+	return 0
+}
+
+// ResponseBytes returns the bytes written to the response body of `ctx`. See `ResponseStatus()`.
+func ResponseBytes(ctx Context) int64 {
+	if wr := coreResponseWriter(ctx.ResponseWriter()); wr != nil {
+		return wr.bytes
+	}
+	return 0
+}
+
+// coreResponseWriter returns the `responseWriter` of espresso, through writers wrapping it with `Unwrap()`.
+func coreResponseWriter(w http.ResponseWriter) *responseWriter {
+	for {
+		switch v := w.(type) {
+		case *responseWriter:
+			return v
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = v.Unwrap()
+		default:
+			return nil
+		}
+	}
+}
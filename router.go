@@ -3,6 +3,8 @@ package espresso
 import (
 	"fmt"
 	"net/http"
+	"reflect"
+	"runtime"
 	"slices"
 	"strings"
 )
@@ -11,19 +13,39 @@ type Router interface {
 	Use(middlewares ...HandleFunc)
 	WithPrefix(path string) Router
 	HandleFunc(handleFunc HandleFunc)
+	HandleAll(service any)
 }
 
 type router struct {
 	prefix      string
 	middlewares []HandleFunc
 	mux         *http.ServeMux
+	registry    *registry
+}
+
+// registry records all registered endpoints.
+type registry struct {
+	names     map[string]string
+	endpoints []registeredEndpoint
+}
+
+type registeredEndpoint struct {
+	name     string
+	endpoint *Endpoint
+}
+
+func newRegistry() *registry {
+	return &registry{
+		names: make(map[string]string),
+	}
 }
 
 func (g *router) WithPrefix(path string) Router {
 	return &router{
 		prefix:      strings.TrimRight(g.prefix, "/") + "/" + strings.Trim(path, "/"),
-		middlewares: g.middlewares[0:len(g.middlewares)],
+		middlewares: slices.Clip(g.middlewares),
 		mux:         g.mux,
+		registry:    g.registry,
 	}
 }
 
@@ -32,28 +54,58 @@ func (g *router) Use(middleware ...HandleFunc) {
 }
 
 func (g *router) HandleFunc(fn HandleFunc) {
-	g.handleFunc(fn)
+	g.handleFunc(fn, funcName(fn))
 }
 
-func (g *router) handleFunc(fn HandleFunc) {
+// HandleAll registers all methods of `service` with the signature `func(espresso.Context) error`.
+// Other methods are skipped. If `service` implements `MiddlewareProvider`, its middlewares are used by all its handlers.
+// Methods are found by reflecting only when registering.
+func (g *router) HandleAll(service any) {
+	v := reflect.ValueOf(service)
+	if !v.IsValid() {
+		panic("HandleAll with a nil service")
+	}
+	t := v.Type()
+
+	r := g
+	if provider, ok := service.(MiddlewareProvider); ok {
+		r = &router{
+			prefix:      g.prefix,
+			middlewares: append(slices.Clip(g.middlewares), provider.Middlewares()...),
+			mux:         g.mux,
+			registry:    g.registry,
+		}
+	}
+
+	for i := 0; i < t.NumMethod(); i++ {
+		fn, ok := v.Method(i).Interface().(func(Context) error)
+		if !ok {
+			continue
+		}
+
+		r.handleFunc(fn, fmt.Sprintf("%s.%s", t, t.Method(i).Name))
+	}
+}
+
+func (g *router) handleFunc(fn HandleFunc, name string) {
 	ctx := newBuildtimeContext()
 
 	defer func() {
 		v := recover()
 		if v != errBuilderEnd {
 			if v == nil {
-				v = fmt.Errorf("should call ctx.Endpoint().End()")
+				v = fmt.Errorf("handler %s should call ctx.Endpoint().End()", name)
 			}
 			panic(v)
 		}
 
-		g.register(ctx, fn)
+		g.register(ctx, fn, name)
 	}()
 
 	_ = fn(ctx)
 }
 
-func (g *router) register(ctx *buildtimeContext, fn HandleFunc) {
+func (g *router) register(ctx *buildtimeContext, fn HandleFunc, name string) {
 	path := strings.TrimRight(g.prefix, "/") + "/" + strings.TrimLeft(ctx.endpoint.Path, "/")
 	chains := slices.Clone(g.middlewares)
 	chains = append(chains, ctx.endpoint.ChainFuncs...)
@@ -64,6 +116,15 @@ func (g *router) register(ctx *buildtimeContext, fn HandleFunc) {
 	endpoint.ChainFuncs = chains
 
 	pattern := ctx.endpoint.Method + " " + path
+	if exist, ok := g.registry.names[pattern]; ok {
+		panic(fmt.Sprintf("duplicate route %q: registered by %s, and again by %s", pattern, exist, name))
+	}
+	g.registry.names[pattern] = name
+	g.registry.endpoints = append(g.registry.endpoints, registeredEndpoint{
+		name:     name,
+		endpoint: &endpoint,
+	})
+
 	g.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
 		ctx := &runtimeContext{
 			ctx:      r.Context(),
@@ -75,3 +136,11 @@ func (g *router) register(ctx *buildtimeContext, fn HandleFunc) {
 		ctx.Next()
 	})
 }
+
+func funcName(fn HandleFunc) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return "unknown"
+	}
+	return f.Name()
+}
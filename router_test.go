@@ -0,0 +1,110 @@
+package espresso_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/googollee/go-espresso"
+)
+
+type bookService struct {
+	title string
+}
+
+func (s *bookService) Middlewares() []espresso.HandleFunc {
+	return []espresso.HandleFunc{func(ctx espresso.Context) error {
+		ctx.ResponseWriter().Header().Set("X-Service", "book")
+		ctx.Next()
+		return nil
+	}}
+}
+
+func (s *bookService) GetBook(ctx espresso.Context) error {
+	var id int
+	if err := ctx.Endpoint(http.MethodGet, "/book/{id}").
+		BindPath("id", &id).
+		End(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(ctx.ResponseWriter(), "book %d: %s", id, s.title)
+	return nil
+}
+
+func (s bookService) ListBooks(ctx espresso.Context) error {
+	if err := ctx.Endpoint(http.MethodGet, "/books").
+		End(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(ctx.ResponseWriter(), "books: %s", s.title)
+	return nil
+}
+
+func (s *bookService) Title() string {
+	return s.title
+}
+
+func TestHandleAll(t *testing.T) {
+	espo := espresso.New()
+	espo.WithPrefix("/v1").HandleAll(&bookService{title: "espresso"})
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	tests := []struct {
+		path     string
+		wantBody string
+	}{
+		{"/v1/book/1", "book 1: espresso"},
+		{"/v1/books", "books: espresso"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			resp, err := http.Get(svr.URL + tc.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if got, want := resp.StatusCode, http.StatusOK; got != want {
+				t.Errorf("resp.StatusCode = %d, want: %d", got, want)
+			}
+
+			if got, want := resp.Header.Get("X-Service"), "book"; got != want {
+				t.Errorf("resp.Header[X-Service] = %q, want: %q", got, want)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := string(body), tc.wantBody; got != want {
+				t.Errorf("resp.Body = %q, want: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestHandleAllDuplicateRoute(t *testing.T) {
+	defer func() {
+		r := recover()
+		msg, _ := r.(string)
+		if want := `duplicate route "GET /books"`; !strings.Contains(msg, want) {
+			t.Errorf("panic = %v, want contains: %s", r, want)
+		}
+		if want := "bookService.ListBooks"; !strings.Contains(msg, want) {
+			t.Errorf("panic = %v, want contains: %s", r, want)
+		}
+	}()
+
+	espo := espresso.New()
+	espo.HandleAll(&bookService{})
+	espo.HandleAll(bookService{})
+}
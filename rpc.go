@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"unsafe"
 )
 
 func RPC[Request, Response any](fn func(Context, Request) (Response, error)) HandleFunc {
+	validator := mustNewValidator[Request]()
+
 	return func(ctx Context) error {
 		var req Request
 		if bctx, ok := ctx.(*buildtimeContext); ok {
@@ -24,16 +27,30 @@ func RPC[Request, Response any](fn func(Context, Request) (Response, error)) Han
 			return Error(http.StatusInternalServerError, errors.New("no codec in the context"))
 		}
 
-		if err := codec.DecodeRequest(ctx, &req); err != nil {
-			return Error(http.StatusBadRequest, fmt.Errorf("can't decode request: %w", err))
+		// Negotiate before handling, to not run the handler if the response can't be encoded.
+		respCodec, err := codec.Negotiate(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := decodeRequest(ctx, codec, &req); err != nil {
+			return err
+		}
+
+		body, err := deferBodyErrors(ctx, validator.validate(unsafe.Pointer(&req)))
+		if err != nil {
+			return err
 		}
 
 		resp, err := fn(ctx, req)
 		if err != nil {
 			return err
 		}
+		if err := body.unreported(); err != nil {
+			return err
+		}
 
-		if err := codec.EncodeResponse(ctx, &resp); err != nil {
+		if err := encodeResponse(ctx, respCodec, responseStatus(resp), &resp); err != nil {
 			return Error(http.StatusInternalServerError, fmt.Errorf("can't encode response: %w", err))
 		}
 
@@ -56,12 +73,17 @@ func RPCRetrive[Response any](fn func(Context) (Response, error)) HandleFunc {
 			return Error(http.StatusInternalServerError, errors.New("no codec in the context"))
 		}
 
+		respCodec, err := codec.Negotiate(ctx)
+		if err != nil {
+			return err
+		}
+
 		resp, err := fn(ctx)
 		if err != nil {
 			return err
 		}
 
-		if err := codec.EncodeResponse(ctx, &resp); err != nil {
+		if err := encodeResponse(ctx, respCodec, responseStatus(resp), &resp); err != nil {
 			return Error(http.StatusInternalServerError, fmt.Errorf("can't encode response: %w", err))
 		}
 
@@ -70,6 +92,8 @@ func RPCRetrive[Response any](fn func(Context) (Response, error)) HandleFunc {
 }
 
 func RPCConsume[Request any](fn func(Context, Request) error) HandleFunc {
+	validator := mustNewValidator[Request]()
+
 	return func(ctx Context) error {
 		var req Request
 		if bctx, ok := ctx.(*buildtimeContext); ok {
@@ -84,15 +108,93 @@ func RPCConsume[Request any](fn func(Context, Request) error) HandleFunc {
 			return Error(http.StatusInternalServerError, errors.New("no codec in the context"))
 		}
 
-		if err := codec.DecodeRequest(ctx, &req); err != nil {
-			return Error(http.StatusBadRequest, fmt.Errorf("can't decode request: %w", err))
+		if err := decodeRequest(ctx, codec, &req); err != nil {
+			return err
 		}
 
-		err := fn(ctx, req)
+		body, err := deferBodyErrors(ctx, validator.validate(unsafe.Pointer(&req)))
 		if err != nil {
 			return err
 		}
 
+		if err := fn(ctx, req); err != nil {
+			return err
+		}
+
+		return body.unreported()
+	}
+}
+
+// bodyErrors are violations of a decoded request body. They're reported by `End()` of the handler with errors of
+// params, so all invalid fields are responded together.
+type bodyErrors struct {
+	errs BindErrors
+}
+
+// deferBodyErrors defers violations in `err` to `End()` of the handler. It returns `err` directly if it can't be
+// deferred.
+func deferBodyErrors(ctx Context, err error) (*bodyErrors, error) {
+	if err == nil {
+		return nil, nil
+	}
+
+	rctx, ok := ctx.(*runtimeContext)
+	var violations ValidationErrors
+	if !ok || !errors.As(err, &violations) {
+		return nil, err
+	}
+
+	rctx.body = &bodyErrors{errs: violations.bindErrors()}
+	return rctx.body, nil
+}
+
+// unreported returns violations which aren't reported by `End()`, if the handler doesn't bind with `ctx.Endpoint()`.
+func (b *bodyErrors) unreported() error {
+	if b == nil || len(b.errs) == 0 {
 		return nil
 	}
+	return b.errs
+}
+
+// DecodeError is the error when RPC handlers fail to decode the request body.
+type DecodeError struct {
+	Err error
+}
+
+func (e DecodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func decodeRequest(ctx Context, codec *Codecs, req any) error {
+	err := codec.DecodeRequest(ctx, req)
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(HTTPError); ok {
+		return Error(StatusOf(err), DecodeError{Err: err})
+	}
+	return Error(http.StatusBadRequest, DecodeError{Err: fmt.Errorf("can't decode request: %w", err)})
+}
+
+// responseStatus returns the status code of the response `resp`. A response type could implement `HTTPCode() int`
+// to respond with a status other than HTTP 200, like HTTP 201.
+func responseStatus(resp any) int {
+	if coder, ok := resp.(interface{ HTTPCode() int }); ok {
+		return coder.HTTPCode()
+	}
+	return http.StatusOK
+}
+
+func mustNewValidator[Request any]() validator {
+	var req Request
+	ret, err := newValidator(reflect.TypeOf(&req).Elem())
+	if err != nil {
+		panic(err.Error())
+	}
+	return ret
 }
@@ -2,31 +2,164 @@ package espresso
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
 	"net/http"
+	"net/url"
+	"reflect"
 	"time"
 )
 
 type runtimeEndpoint struct {
+	ctx      context.Context
 	request  *http.Request
 	endpoint *Endpoint
+	query    url.Values
+	body     *bodyErrors
 	err      BindErrors
 }
 
-func (e *runtimeEndpoint) BindPath(key string, v any) EndpointBuilder {
-	binder, ok := e.endpoint.PathParams[key]
+func (e *runtimeEndpoint) BindPath(key string, v any, opts ...BindOption) EndpointBuilder {
+	return e.bind(e.endpoint.PathParams, key, v)
+}
+
+func (e *runtimeEndpoint) BindQuery(key string, v any, opts ...BindOption) EndpointBuilder {
+	return e.bind(e.endpoint.QueryParams, key, v)
+}
+
+func (e *runtimeEndpoint) BindForm(key string, v any, opts ...BindOption) EndpointBuilder {
+	return e.bind(e.endpoint.FormParams, key, v)
+}
+
+func (e *runtimeEndpoint) BindHead(key string, v any, opts ...BindOption) EndpointBuilder {
+	return e.bind(e.endpoint.HeadParams, key, v)
+}
+
+func (e *runtimeEndpoint) BindFile(key string, v any, opts ...BindOption) EndpointBuilder {
+	return e.bind(e.endpoint.FileParams, key, v)
+}
+
+func (e *runtimeEndpoint) BindStruct(v any) EndpointBuilder {
+	fields, ok := e.endpoint.structs[reflect.TypeOf(v)]
 	if !ok {
 		return e
 	}
 
-	strV := e.request.PathValue(key)
-	if err := binder.Func(v, strV); err != nil {
-		e.err = append(e.err, errorBind(binder, err))
+	base := reflect.ValueOf(v).UnsafePointer()
+	for _, field := range fields {
+		var err error
+		if field.param.From == BindFileParam {
+			err = e.bindFile(field.param, field.pointer(base))
+		} else {
+			var values []string
+			values, err = e.values(field.param.From, field.param.Key)
+			if err == nil {
+				err = field.set(base, values)
+			}
+		}
+		if err != nil {
+			e.err = append(e.err, errorBind(field.param, err))
+		}
 	}
 
 	return e
 }
 
+func (e *runtimeEndpoint) bind(params map[string]BindParam, key string, v any) EndpointBuilder {
+	binder, ok := params[key]
+	if !ok {
+		return e
+	}
+
+	e.bindParam(binder, v)
+
+	return e
+}
+
+func (e *runtimeEndpoint) bindParam(binder BindParam, v any) {
+	var err error
+	if binder.From == BindFileParam {
+		err = e.bindFile(binder, v)
+	} else {
+		var values []string
+		values, err = e.values(binder.From, binder.Key)
+		if err == nil {
+			err = bindValues(binder, values, v)
+		}
+	}
+	if err != nil {
+		e.err = append(e.err, errorBind(binder, err))
+	}
+}
+
+func (e *runtimeEndpoint) bindFile(binder BindParam, v any) error {
+	var files []*multipart.FileHeader
+	if isMultipart(e.request) {
+		if err := parseMultipart(e.ctx, e.request); err != nil {
+			return err
+		}
+		files = e.request.MultipartForm.File[binder.Key]
+	}
+
+	return bindFiles(binder, multipartConfig(e.ctx), files, v)
+}
+
+func (e *runtimeEndpoint) values(src BindSource, key string) ([]string, error) {
+	switch src {
+	case BindPathParam:
+		if v := e.request.PathValue(key); v != "" {
+			return []string{v}, nil
+		}
+		return nil, nil
+	case BindQueryParam:
+		if e.query == nil {
+			e.query = e.request.URL.Query()
+		}
+		return e.query[key], nil
+	case BindFormParam:
+		var err error
+		if isMultipart(e.request) {
+			err = parseMultipart(e.ctx, e.request)
+		} else {
+			err = parseForm(e.ctx, e.request)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return e.request.PostForm[key], nil
+	case BindHeadParam:
+		return e.request.Header.Values(key), nil
+	}
+
+	return nil, fmt.Errorf("not support bind type %d", src)
+}
+
+// parseForm parses a url-encoded body once, with `DecodeOptions.MaxBodySize`.
+func parseForm(ctx context.Context, r *http.Request) error {
+	if r.PostForm != nil {
+		return nil
+	}
+
+	if limit := requestDecodeOptions(ctx).MaxBodySize; limit > 0 && r.Body != nil {
+		r.Body = http.MaxBytesReader(nil, r.Body, limit)
+	}
+
+	err := r.ParseForm()
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return errBodyTooLarge(maxBytesErr.Limit)
+	}
+
+	return err
+}
+
 func (e *runtimeEndpoint) End() BindErrors {
+	if body := e.body; body != nil && len(body.errs) > 0 {
+		e.err = append(e.err, body.errs...)
+		body.errs = nil
+	}
 	return e.err
 }
 
@@ -38,12 +171,16 @@ type runtimeContext struct {
 
 	err        error
 	chainIndex int
+	// body has violations of the request body of an RPC handler, reported by `End()` with errors of params.
+	body *bodyErrors
 }
 
 func (c *runtimeContext) Endpoint(method, path string, mid ...HandleFunc) EndpointBuilder {
 	return &runtimeEndpoint{
+		ctx:      c,
 		request:  c.request,
 		endpoint: c.endpoint,
+		body:     c.body,
 	}
 }
 
@@ -74,6 +211,7 @@ func (c *runtimeContext) WithParent(ctx context.Context) Context {
 		response:   c.response,
 		err:        c.err,
 		chainIndex: c.chainIndex,
+		body:       c.body,
 	}
 }
 
@@ -85,9 +223,14 @@ func (c *runtimeContext) WithResponseWriter(w http.ResponseWriter) Context {
 		response:   w,
 		err:        c.err,
 		chainIndex: c.chainIndex,
+		body:       c.body,
 	}
 }
 
+func (c *runtimeContext) Multipart() (*MultipartReader, error) {
+	return newMultipartReader(c, c.request)
+}
+
 func (c *runtimeContext) Request() *http.Request {
 	return c.request
 }
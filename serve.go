@@ -0,0 +1,214 @@
+package espresso
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ServeOption configures the server of `Espresso.Run` and `Espresso.Serve`.
+type ServeOption func(*serveConfig)
+
+type serveConfig struct {
+	readHeaderTimeout time.Duration
+	readTimeout       time.Duration
+	writeTimeout      time.Duration
+	idleTimeout       time.Duration
+	shutdownTimeout   time.Duration
+	shutdownDelay     time.Duration
+	onShutdown        []func(context.Context) error
+}
+
+func defaultServeConfig() serveConfig {
+	return serveConfig{
+		readHeaderTimeout: 10 * time.Second,
+		readTimeout:       30 * time.Second,
+		writeTimeout:      60 * time.Second,
+		idleTimeout:       120 * time.Second,
+		shutdownTimeout:   30 * time.Second,
+	}
+}
+
+// ReadHeaderTimeout sets `http.Server.ReadHeaderTimeout`. The default is 10s.
+func ReadHeaderTimeout(d time.Duration) ServeOption {
+	return func(c *serveConfig) {
+		c.readHeaderTimeout = d
+	}
+}
+
+// ReadTimeout sets `http.Server.ReadTimeout`. The default is 30s.
+func ReadTimeout(d time.Duration) ServeOption {
+	return func(c *serveConfig) {
+		c.readTimeout = d
+	}
+}
+
+// WriteTimeout sets `http.Server.WriteTimeout`. The default is 60s. Set 0 for streaming responses.
+func WriteTimeout(d time.Duration) ServeOption {
+	return func(c *serveConfig) {
+		c.writeTimeout = d
+	}
+}
+
+// IdleTimeout sets `http.Server.IdleTimeout`. The default is 120s.
+func IdleTimeout(d time.Duration) ServeOption {
+	return func(c *serveConfig) {
+		c.idleTimeout = d
+	}
+}
+
+// ShutdownTimeout sets the max duration to drain in-flight requests and run shutdown hooks. The default is 30s.
+func ShutdownTimeout(d time.Duration) ServeOption {
+	return func(c *serveConfig) {
+		c.shutdownTimeout = d
+	}
+}
+
+// ShutdownDelay sets the duration to keep serving after `ctx` is done, with failing readiness checks, to let load
+// balancers stop sending new requests. The default is 0.
+func ShutdownDelay(d time.Duration) ServeOption {
+	return func(c *serveConfig) {
+		c.shutdownDelay = d
+	}
+}
+
+// OnShutdown adds a hook running after draining in-flight requests, before closing modules. Hooks run in the
+// reverse order of adding.
+func OnShutdown(fn func(context.Context) error) ServeOption {
+	return func(c *serveConfig) {
+		c.onShutdown = append(c.onShutdown, fn)
+	}
+}
+
+// Run listens on the TCP address `addr` and serves requests until `ctx` is done. See `Espresso.Serve`.
+func (s *Espresso) Run(ctx context.Context, addr string, opts ...ServeOption) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(ctx, l, opts...)
+}
+
+// Serve starts modules with `Espresso.Start` and serves requests from the listener `l`, until `ctx` is done or
+// serving fails. Then it shuts down gracefully:
+//   - stops accepting new connections and waits for in-flight requests.
+//   - runs hooks added by `OnShutdown`.
+//   - runs callbacks added by `OnClose` when creating modules, in the reverse order of adding.
+//
+// Serve returns nil if shutting down without any error.
+func (s *Espresso) Serve(ctx context.Context, l net.Listener, opts ...ServeOption) error {
+	cfg := defaultServeConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if err := s.Start(ctx); err != nil {
+		_ = l.Close()
+		return errors.Join(err, s.Close(context.WithoutCancel(ctx)))
+	}
+
+	svr := &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: cfg.readHeaderTimeout,
+		ReadTimeout:       cfg.readTimeout,
+		WriteTimeout:      cfg.writeTimeout,
+		IdleTimeout:       cfg.idleTimeout,
+		BaseContext: func(net.Listener) context.Context {
+			// Requests are not canceled by `ctx`, to finish in-flight requests when shutting down.
+			return context.WithoutCancel(ctx)
+		},
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- svr.Serve(l)
+	}()
+
+	var errs []error
+	select {
+	case err := <-serveErr:
+		errs = append(errs, err)
+	case <-ctx.Done():
+		s.shuttingDown.Store(true)
+		time.Sleep(cfg.shutdownDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cfg.shutdownTimeout)
+	defer cancel()
+
+	if err := svr.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, fmt.Errorf("espresso: shutdown server: %w", err))
+	}
+	if len(errs) == 0 {
+		if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+			errs = append(errs, err)
+		}
+	}
+
+	for i := len(cfg.onShutdown) - 1; i >= 0; i-- {
+		if err := cfg.onShutdown[i](shutdownCtx); err != nil {
+			errs = append(errs, fmt.Errorf("espresso: shutdown hook: %w", err))
+		}
+	}
+
+	errs = append(errs, s.Close(shutdownCtx))
+
+	return errors.Join(errs...)
+}
+
+type closersKey struct{}
+
+// closers are callbacks to close modules.
+type closers struct {
+	mu  sync.Mutex
+	fns []func(context.Context) error
+}
+
+func withClosers(ctx context.Context, c *closers) context.Context {
+	return context.WithValue(ctx, closersKey{}, c)
+}
+
+// OnClose adds a callback `fn` to close the module when the server shuts down. It should be called in the function
+// creating a module instance, with the context of that function:
+//
+//	var DBModule = module.New[*sql.DB]()
+//	var ProvideDB = DBModule.ProvideWithFunc(func(ctx context.Context) (*sql.DB, error) {
+//	  db, err := sql.Open("postgres", dsn)
+//	  if err != nil {
+//	    return nil, err
+//	  }
+//	  espresso.OnClose(ctx, func(context.Context) error { return db.Close() })
+//	  return db, nil
+//	})
+//
+// Callbacks run in the reverse order of adding by `Espresso.Close`, so a module closes before modules it depends on.
+func OnClose(ctx context.Context, fn func(context.Context) error) {
+	c, ok := ctx.Value(closersKey{}).(*closers)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fns = append(c.fns, fn)
+}
+
+func (c *closers) close(ctx context.Context) error {
+	c.mu.Lock()
+	fns := c.fns
+	c.fns = nil
+	c.mu.Unlock()
+
+	var errs []error
+	for i := len(fns) - 1; i >= 0; i-- {
+		if err := fns[i](ctx); err != nil {
+			errs = append(errs, fmt.Errorf("espresso: close module: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,168 @@
+package espresso_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/googollee/module"
+
+	"github.com/googollee/go-espresso"
+)
+
+func TestServe(t *testing.T) {
+	type DB struct{}
+	type Cache struct{}
+	dbModule := module.New[*DB]()
+	cacheModule := module.New[*Cache]()
+
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+			return nil
+		}
+	}
+
+	espo := espresso.New()
+	espo.AddModule(dbModule.ProvideWithFunc(func(ctx context.Context) (*DB, error) {
+		espresso.OnClose(ctx, record("close db"))
+		return &DB{}, nil
+	}))
+	espo.AddModule(cacheModule.ProvideWithFunc(func(ctx context.Context) (*Cache, error) {
+		// Cache depends on DB.
+		_ = dbModule.Value(ctx)
+		espresso.OnClose(ctx, record("close cache"))
+		return &Cache{}, nil
+	}))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		if err := ctx.Endpoint(http.MethodGet, "/slow").End(); err != nil {
+			return err
+		}
+
+		close(started)
+		<-release
+
+		if dbModule.Value(ctx) == nil || cacheModule.Value(ctx) == nil {
+			return errors.New("no module")
+		}
+
+		_, _ = io.WriteString(ctx.ResponseWriter(), "done")
+		return nil
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- espo.Serve(ctx, l,
+			espresso.ShutdownTimeout(5*time.Second),
+			espresso.OnShutdown(record("shutdown 1")),
+			espresso.OnShutdown(record("shutdown 2")))
+	}()
+
+	type response struct {
+		body string
+		err  error
+	}
+	respCh := make(chan response, 1)
+	go func() {
+		resp, err := http.Get("http://" + l.Addr().String() + "/slow")
+		if err != nil {
+			respCh <- response{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		respCh <- response{body: string(body), err: err}
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-serveErr:
+		t.Fatalf("Serve() returns %v before finishing in-flight requests", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+
+	resp := <-respCh
+	if resp.err != nil {
+		t.Fatalf("in-flight request error: %v", resp.err)
+	}
+	if got, want := resp.body, "done"; got != want {
+		t.Errorf("resp.Body = %q, want: %q", got, want)
+	}
+
+	if err := <-serveErr; err != nil {
+		t.Fatalf("Serve() error: %v", err)
+	}
+
+	if got, want := events, []string{"shutdown 2", "shutdown 1", "close cache", "close db"}; !slices.Equal(got, want) {
+		t.Errorf("events = %v, want: %v", got, want)
+	}
+}
+
+func TestServeModuleError(t *testing.T) {
+	type DB struct{}
+	type Cache struct{}
+	dbModule := module.New[*DB]()
+	cacheModule := module.New[*Cache]()
+
+	var closed bool
+	espo := espresso.New()
+	espo.AddModule(dbModule.ProvideWithFunc(func(ctx context.Context) (*DB, error) {
+		espresso.OnClose(ctx, func(context.Context) error {
+			closed = true
+			return nil
+		})
+		return &DB{}, nil
+	}))
+	espo.AddModule(cacheModule.ProvideWithFunc(func(ctx context.Context) (*Cache, error) {
+		_ = dbModule.Value(ctx)
+		return nil, errors.New("can't connect")
+	}))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = espo.Serve(context.Background(), l)
+	if err == nil {
+		t.Fatal("Serve() returns nil, want an error")
+	}
+	if got, want := err.Error(), "espresso: create modules: creating with module *espresso_test.Cache: can't connect"; got != want {
+		t.Errorf("Serve() error = %q, want: %q", got, want)
+	}
+	if !closed {
+		t.Errorf("created modules are not closed")
+	}
+}
+
+func TestRunInvalidAddr(t *testing.T) {
+	espo := espresso.New()
+	if err := espo.Run(context.Background(), "invalid:addr:0"); err == nil {
+		t.Fatal("Run() returns nil, want an error")
+	}
+}
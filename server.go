@@ -1,32 +1,54 @@
 package espresso
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"sync"
+	"sync/atomic"
 
 	"github.com/googollee/module"
 )
 
 type Espresso struct {
-	repo   *module.Repo
-	mux    *http.ServeMux
-	router Router
+	repo     *module.Repo
+	mux      *http.ServeMux
+	registry *registry
+	router   Router
+	closers  closers
+	health   healthChecks
+
+	shuttingDown atomic.Bool
+
+	started   atomic.Bool
+	startOnce sync.Once
+	startErr  error
+	modules   context.Context
 }
 
 func New() *Espresso {
 	ret := &Espresso{
-		repo: module.NewRepo(),
-		mux:  http.NewServeMux(),
+		repo:     module.NewRepo(),
+		mux:      http.NewServeMux(),
+		registry: newRegistry(),
 	}
 	ret.router = &router{
-		mux: ret.mux,
+		mux:      ret.mux,
+		registry: ret.registry,
 	}
 
-	ret.Use(logHandling, cacheAllError)
+	ret.Use(requestIDHandling, logHandling, cacheAllError)
 
 	return ret
 }
 
+// AddModule adds providers of modules. It panics if called after `Start`, since instances are already created.
 func (s *Espresso) AddModule(provider ...module.Provider) {
+	if s.started.Load() {
+		panic("espresso: AddModule() is called after Start()")
+	}
+
 	for _, p := range provider {
 		s.repo.Add(p)
 	}
@@ -40,16 +62,86 @@ func (s *Espresso) HandleFunc(handleFunc HandleFunc) {
 	s.router.HandleFunc(handleFunc)
 }
 
+func (s *Espresso) HandleAll(service any) {
+	s.router.HandleAll(service)
+}
+
 func (s *Espresso) WithPrefix(path string) Router {
 	return s.router.WithPrefix(path)
 }
 
+// Start creates instances of all modules added by `AddModule`, and returns the error if any module fails. Instances
+// are created only once and shared by all requests. Adding modules after `Start` panics.
+//
+// `Start` is called by `Serve` and `Run`, or by the first request if not called. Calling it before serving reports
+// errors of modules early.
+func (s *Espresso) Start(ctx context.Context) error {
+	s.startOnce.Do(func() {
+		s.started.Store(true)
+
+		ctx, err := s.repo.InjectTo(withHealthChecks(withClosers(context.WithoutCancel(ctx), &s.closers), &s.health))
+		if err != nil {
+			s.startErr = fmt.Errorf("espresso: create modules: %w", err)
+			return
+		}
+		s.modules = ctx
+	})
+
+	return s.startErr
+}
+
+// Close runs callbacks added by `OnClose` to close modules. It's called by `Serve` and `Run` when shutting down.
+func (s *Espresso) Close(ctx context.Context) error {
+	return s.closers.close(ctx)
+}
+
 func (s *Espresso) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ctx, err := s.repo.InjectTo(r.Context())
-	if err != nil {
-		panic(err)
+	if err := s.Start(context.Background()); err != nil {
+		slog.Default().ErrorContext(r.Context(), "espresso can't serve", "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
 	}
 
-	r = r.WithContext(ctx)
+	r = r.WithContext(&modulesContext{Context: r.Context(), modules: s.modules})
 	s.mux.ServeHTTP(w, r)
+
+	// `http.Server` only removes temporary files of the original request, not of this copy.
+	if r.MultipartForm != nil {
+		_ = r.MultipartForm.RemoveAll()
+	}
+}
+
+// modulesContext is the context of a request, with module instances created by `Start`. Values in the request
+// context override module instances.
+type modulesContext struct {
+	context.Context
+	modules context.Context
+}
+
+func (c *modulesContext) Value(key any) any {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+	return c.modules.Value(key)
+}
+
+// ProvidePerRequest returns a middleware providing an instance of the module `m` for each request, created by `fn`.
+// Instances from `ProvidePerRequest` override instances created by `Start`. If `fn` returns an error, the request
+// fails with that error.
+//
+//	svr.Use(espresso.ProvidePerRequest(UserModule, func(ctx espresso.Context) (*User, error) {
+//	  return loadUser(ctx, ctx.Request().Header.Get("Authorization"))
+//	}))
+func ProvidePerRequest[T any](m module.Module[T], fn func(Context) (T, error)) HandleFunc {
+	return func(ctx Context) error {
+		v, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+
+		ctx = ctx.WithParent(m.With(ctx, v))
+		ctx.Next()
+
+		return ctx.Err()
+	}
 }
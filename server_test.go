@@ -0,0 +1,256 @@
+package espresso_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/googollee/module"
+
+	"github.com/googollee/go-espresso"
+)
+
+func TestStart(t *testing.T) {
+	type DB struct{}
+	dbModule := module.New[*DB]()
+
+	var created int
+	espo := espresso.New()
+	espo.AddModule(dbModule.ProvideWithFunc(func(context.Context) (*DB, error) {
+		created++
+		return nil, errors.New("can't connect")
+	}))
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		if err := ctx.Endpoint(http.MethodGet, "/").End(); err != nil {
+			return err
+		}
+		return nil
+	})
+
+	wantErr := "espresso: create modules: creating with module *espresso_test.DB: can't connect"
+	for i := 0; i < 2; i++ {
+		err := espo.Start(context.Background())
+		if err == nil {
+			t.Fatal("Start() returns nil, want an error")
+		}
+		if got := err.Error(); got != wantErr {
+			t.Errorf("Start() error = %q, want: %q", got, wantErr)
+		}
+	}
+
+	resp := httptest.NewRecorder()
+	espo.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got, want := resp.Code, http.StatusInternalServerError; got != want {
+		t.Errorf("resp.Code = %d, want: %d", got, want)
+	}
+
+	if got, want := created, 1; got != want {
+		t.Errorf("created = %d, want: %d", got, want)
+	}
+}
+
+func TestOpenAPIBeforeAddModule(t *testing.T) {
+	espo := espresso.New()
+	espo.HandleFunc(espresso.RPC(func(ctx espresso.Context, req string) (string, error) {
+		if err := ctx.Endpoint(http.MethodPost, "/echo").End(); err != nil {
+			return "", err
+		}
+		return req, nil
+	}))
+
+	_ = espo.OpenAPI()
+	espo.AddModule(espresso.ProvideCodecs)
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(`"espresso"`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	espo.ServeHTTP(resp, req)
+
+	if got, want := resp.Code, http.StatusOK; got != want {
+		t.Errorf("resp.Code = %d, want: %d, body: %q", got, want, resp.Body.String())
+	}
+}
+
+func TestAddModuleAfterStart(t *testing.T) {
+	espo := espresso.New()
+	if err := espo.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("AddModule() after Start() doesn't panic")
+		}
+	}()
+	espo.AddModule(espresso.ProvideCodecs)
+}
+
+func TestModulesInRequestContext(t *testing.T) {
+	type DB struct{}
+	dbModule := module.New[*DB]()
+
+	espo := espresso.New()
+	espo.AddModule(dbModule.ProvideValue(&DB{}))
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		if err := ctx.Endpoint(http.MethodGet, "/").End(); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(ctx.ResponseWriter(), "ctx=%t request=%t", dbModule.Value(ctx) != nil, dbModule.Value(ctx.Request().Context()) != nil)
+		return nil
+	})
+
+	resp := httptest.NewRecorder()
+	espo.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got, want := resp.Body.String(), "ctx=true request=true"; got != want {
+		t.Errorf("resp.Body = %q, want: %q", got, want)
+	}
+}
+
+type wrapWriter struct {
+	http.ResponseWriter
+}
+
+func (w wrapWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func TestResponseStatus(t *testing.T) {
+	var status int
+	var bytes int64
+
+	espo := espresso.New()
+	espo.Use(func(ctx espresso.Context) error {
+		ctx.Next()
+		status, bytes = espresso.ResponseStatus(ctx), espresso.ResponseBytes(ctx)
+		return ctx.Err()
+	}, func(ctx espresso.Context) error {
+		ctx = ctx.WithResponseWriter(wrapWriter{ctx.ResponseWriter()})
+		ctx.Next()
+		return ctx.Err()
+	})
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		if err := ctx.Endpoint(http.MethodPost, "/").End(); err != nil {
+			return err
+		}
+
+		ctx.ResponseWriter().WriteHeader(http.StatusCreated)
+		fmt.Fprint(ctx.ResponseWriter(), "espresso")
+		return nil
+	})
+
+	espo.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil))
+
+	if got, want := status, http.StatusCreated; got != want {
+		t.Errorf("ResponseStatus() = %d, want: %d", got, want)
+	}
+	if got, want := bytes, int64(len("espresso")); got != want {
+		t.Errorf("ResponseBytes() = %d, want: %d", got, want)
+	}
+}
+
+func TestProvidePerRequest(t *testing.T) {
+	type User struct {
+		Name string
+	}
+	userModule := module.New[*User]()
+
+	espo := espresso.New()
+	espo.AddModule(userModule.ProvideValue(&User{Name: "anonymous"}))
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		if err := ctx.Endpoint(http.MethodGet, "/global").End(); err != nil {
+			return err
+		}
+
+		fmt.Fprint(ctx.ResponseWriter(), userModule.Value(ctx).Name)
+		return nil
+	})
+
+	router := espo.WithPrefix("/user")
+	router.Use(espresso.ProvidePerRequest(userModule, func(ctx espresso.Context) (*User, error) {
+		name := ctx.Request().Header.Get("X-User")
+		if name == "" {
+			return nil, espresso.Error(http.StatusUnauthorized, errors.New("no user"))
+		}
+		return &User{Name: name}, nil
+	}))
+	router.HandleFunc(func(ctx espresso.Context) error {
+		if err := ctx.Endpoint(http.MethodGet, "/name").End(); err != nil {
+			return err
+		}
+
+		fmt.Fprint(ctx.ResponseWriter(), userModule.Value(ctx).Name)
+		return nil
+	})
+
+	if err := espo.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		user     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "Global",
+			path:     "/global",
+			user:     "espresso",
+			wantCode: http.StatusOK,
+			wantBody: "anonymous",
+		},
+		{
+			name:     "PerRequest",
+			path:     "/user/name",
+			user:     "espresso",
+			wantCode: http.StatusOK,
+			wantBody: "espresso",
+		},
+		{
+			name:     "Error",
+			path:     "/user/name",
+			wantCode: http.StatusUnauthorized,
+			wantBody: "no user",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, svr.URL+tc.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("X-User", tc.user)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if got, want := resp.StatusCode, tc.wantCode; got != want {
+				t.Errorf("resp.StatusCode = %d, want: %d", got, want)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := string(body), tc.wantBody; got != want {
+				t.Errorf("resp.Body = %q, want: %q", got, want)
+			}
+		})
+	}
+}
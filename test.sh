@@ -1,3 +1,8 @@
 #!/bin/sh
 
-GODEBUG=httpmuxgo121=0 go test -v -race -cover ./...
+set -e
+
+# Codecs with third-party dependencies are separate modules, so test each module.
+for mod in $(find . -name go.mod -exec dirname {} \;); do
+	(cd "$mod" && GODEBUG=httpmuxgo121=0 go test -v -race -cover ./...)
+done
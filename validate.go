@@ -0,0 +1,788 @@
+package espresso
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// Rule is a constraint to validate a bound param or a field of a decoded request.
+// `Name` and `Arg` describe the rule, like `min` with `1`, for documentation generators.
+type Rule struct {
+	Name string
+	Arg  any
+
+	// elem means the rule applies to each element if the value is a slice.
+	elem bool
+	// raw means the rule checks the value without dereferencing pointers.
+	raw    bool
+	accept func(reflect.Type) bool
+	// check builds the check of values of the type when registering handlers. The check gets the raw pointer to a value.
+	check func(reflect.Type) (func(p unsafe.Pointer) error, error)
+}
+
+// RuleError describes the error when a value violates a rule.
+type RuleError struct {
+	Rule string
+	Err  error
+}
+
+func (e RuleError) Error() string {
+	return e.Err.Error()
+}
+
+func (e RuleError) Unwrap() error {
+	return e.Err
+}
+
+// Min requires a number to be greater than or equal to `n`.
+func Min(n float64) BindOption {
+	return withRule(minRule(n))
+}
+
+// Max requires a number to be less than or equal to `n`.
+func Max(n float64) BindOption {
+	return withRule(maxRule(n))
+}
+
+// MinLength requires the length of a string or a slice to be at least `n`.
+func MinLength(n int) BindOption {
+	return withRule(minLengthRule(n))
+}
+
+// MaxLength requires the length of a string or a slice to be at most `n`.
+func MaxLength(n int) BindOption {
+	return withRule(maxLengthRule(n))
+}
+
+// Pattern requires a string to match the regular expression `pattern`.
+func Pattern(pattern string) BindOption {
+	return withRule(patternRule(regexp.MustCompile(pattern)))
+}
+
+// Enum requires a value to be one of `values`. Values are parsed to the type of the value like params, when registering
+// handlers.
+func Enum(values ...string) BindOption {
+	return withRule(enumRule(values))
+}
+
+// Check requires a value to pass the custom predicate `fn`. The predicate gets the bound value, not the pointer.
+func Check(name string, fn func(v any) error) BindOption {
+	return withRule(customRule(name, fn))
+}
+
+var customRules sync.Map // string -> func(any) error
+
+// RegisterRule registers a custom predicate with `name`, which could be used in `validate` struct tags.
+// It should be called before registering handlers, usually in `init()`.
+func RegisterRule(name string, fn func(v any) error) {
+	customRules.Store(name, fn)
+}
+
+func withRule(rule Rule) BindOption {
+	return func(p *BindParam) {
+		p.Rules = append(p.Rules, rule)
+	}
+}
+
+// compile builds the check of values of type `t` when registering handlers. Values of builtin types are checked
+// without reflecting when handling requests.
+func (r Rule) compile(t reflect.Type) (func(p unsafe.Pointer) error, error) {
+	switch {
+	case !r.raw && t.Kind() == reflect.Pointer:
+		check, err := r.compile(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return func(p unsafe.Pointer) error {
+			if p = *(*unsafe.Pointer)(p); p == nil {
+				return nil
+			}
+			return check(p)
+		}, nil
+
+	case !r.raw && t.Kind() == reflect.Interface:
+		// Dynamic types of interfaces are known only when handling requests, so it's checked by reflecting.
+		check, err := r.check(t)
+		if err != nil {
+			return nil, err
+		}
+		return func(p unsafe.Pointer) error {
+			if reflect.NewAt(t, p).Elem().IsNil() {
+				return nil
+			}
+			if err := check(p); err != nil {
+				return RuleError{Rule: r.Name, Err: err}
+			}
+			return nil
+		}, nil
+
+	case r.elem && t.Kind() == reflect.Slice:
+		check, err := r.compile(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		elems, size := sliceElems(t), t.Elem().Size()
+		return func(p unsafe.Pointer) error {
+			data, n := elems(p)
+			for i := 0; i < n; i++ {
+				if err := check(unsafe.Add(data, uintptr(i)*size)); err != nil {
+					return BindIndexError{Index: i, Err: err}
+				}
+			}
+			return nil
+		}, nil
+	}
+
+	check, err := r.check(t)
+	if err != nil {
+		return nil, err
+	}
+	return func(p unsafe.Pointer) error {
+		if err := check(p); err != nil {
+			return RuleError{Rule: r.Name, Err: err}
+		}
+		return nil
+	}, nil
+}
+
+// compileRules builds checks of `rules` for values of type `t`.
+func compileRules(t reflect.Type, rules []Rule) ([]func(p unsafe.Pointer) error, error) {
+	ret := make([]func(p unsafe.Pointer) error, 0, len(rules))
+	for _, rule := range rules {
+		check, err := rule.compile(t)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		ret = append(ret, check)
+	}
+	return ret, nil
+}
+
+func (r Rule) accepts(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if r.elem && t.Kind() == reflect.Slice {
+		t = t.Elem()
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+	}
+
+	return r.accept(t)
+}
+
+func acceptAny(reflect.Type) bool {
+	return true
+}
+
+func acceptNumber(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func acceptString(t reflect.Type) bool {
+	return t.Kind() == reflect.String
+}
+
+func acceptLength(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// intReader returns the function reading an integer at a raw pointer, by the kind of the integer type.
+func intReader(k reflect.Kind) func(p unsafe.Pointer) int64 {
+	switch k {
+	case reflect.Int:
+		return func(p unsafe.Pointer) int64 { return int64(*(*int)(p)) }
+	case reflect.Int8:
+		return func(p unsafe.Pointer) int64 { return int64(*(*int8)(p)) }
+	case reflect.Int16:
+		return func(p unsafe.Pointer) int64 { return int64(*(*int16)(p)) }
+	case reflect.Int32:
+		return func(p unsafe.Pointer) int64 { return int64(*(*int32)(p)) }
+	case reflect.Int64:
+		return func(p unsafe.Pointer) int64 { return *(*int64)(p) }
+	}
+	return nil
+}
+
+// uintReader returns the function reading an unsigned integer at a raw pointer, by the kind of the integer type.
+func uintReader(k reflect.Kind) func(p unsafe.Pointer) uint64 {
+	switch k {
+	case reflect.Uint:
+		return func(p unsafe.Pointer) uint64 { return uint64(*(*uint)(p)) }
+	case reflect.Uint8:
+		return func(p unsafe.Pointer) uint64 { return uint64(*(*uint8)(p)) }
+	case reflect.Uint16:
+		return func(p unsafe.Pointer) uint64 { return uint64(*(*uint16)(p)) }
+	case reflect.Uint32:
+		return func(p unsafe.Pointer) uint64 { return uint64(*(*uint32)(p)) }
+	case reflect.Uint64:
+		return func(p unsafe.Pointer) uint64 { return *(*uint64)(p) }
+	}
+	return nil
+}
+
+// floatReader returns the function reading a float at a raw pointer, by the kind of the float type.
+func floatReader(k reflect.Kind) func(p unsafe.Pointer) float64 {
+	switch k {
+	case reflect.Float32:
+		return func(p unsafe.Pointer) float64 { return float64(*(*float32)(p)) }
+	case reflect.Float64:
+		return func(p unsafe.Pointer) float64 { return *(*float64)(p) }
+	}
+	return nil
+}
+
+// numberReader returns the function reading a number at a raw pointer as a float.
+func numberReader(k reflect.Kind) func(p unsafe.Pointer) float64 {
+	if read := intReader(k); read != nil {
+		return func(p unsafe.Pointer) float64 { return float64(read(p)) }
+	}
+	if read := uintReader(k); read != nil {
+		return func(p unsafe.Pointer) float64 { return float64(read(p)) }
+	}
+	return floatReader(k)
+}
+
+func readString(p unsafe.Pointer) string {
+	return *(*string)(p)
+}
+
+func readBool(p unsafe.Pointer) bool {
+	return *(*bool)(p)
+}
+
+// lengthReader returns the function reading the length of a value of type `t` at a raw pointer. The length of a string
+// is the count of runes.
+func lengthReader(t reflect.Type) func(p unsafe.Pointer) int {
+	switch t.Kind() {
+	case reflect.String:
+		return func(p unsafe.Pointer) int { return utf8.RuneCountInString(readString(p)) }
+	case reflect.Array:
+		n := t.Len()
+		return func(unsafe.Pointer) int { return n }
+	case reflect.Slice:
+		elems := sliceElems(t)
+		return func(p unsafe.Pointer) int {
+			_, n := elems(p)
+			return n
+		}
+	}
+	return func(p unsafe.Pointer) int { return reflect.NewAt(t, p).Elem().Len() }
+}
+
+// zeroChecker returns the function checking if a value of type `t` at a raw pointer is zero.
+func zeroChecker(t reflect.Type) func(p unsafe.Pointer) bool {
+	if builtin, ok := builtinTypes[t]; ok {
+		return builtin.isZero
+	}
+
+	k := t.Kind()
+	switch {
+	case k == reflect.String:
+		return func(p unsafe.Pointer) bool { return readString(p) == "" }
+	case k == reflect.Bool:
+		return func(p unsafe.Pointer) bool { return !readBool(p) }
+	case k == reflect.Pointer:
+		return func(p unsafe.Pointer) bool { return *(*unsafe.Pointer)(p) == nil }
+	case k == reflect.Slice:
+		elems := sliceElems(t)
+		return func(p unsafe.Pointer) bool {
+			data, _ := elems(p)
+			return data == nil
+		}
+	case numberReader(k) != nil:
+		read := numberReader(k)
+		return func(p unsafe.Pointer) bool { return read(p) == 0 }
+	}
+
+	return func(p unsafe.Pointer) bool { return reflect.NewAt(t, p).Elem().IsZero() }
+}
+
+// valueReader returns the function reading a value of type `t` at a raw pointer to an interface.
+func valueReader(t reflect.Type) func(p unsafe.Pointer) any {
+	if builtin, ok := builtinTypes[t]; ok {
+		return builtin.value
+	}
+	return func(p unsafe.Pointer) any { return reflect.NewAt(t, p).Elem().Interface() }
+}
+
+// enumSet parses `values` to the type `t` like params, and returns the function checking if a value read by `read` is
+// one of them.
+func enumSet[T comparable](t reflect.Type, values []string, read func(p unsafe.Pointer) T) (func(p unsafe.Pointer) bool, error) {
+	_, bind := getBindFunc(reflect.New(t).Interface())
+	if bind == nil {
+		return nil, fmt.Errorf("can't parse values to type %s", t)
+	}
+
+	set := make(map[T]struct{}, len(values))
+	for _, value := range values {
+		v := reflect.New(t)
+		if err := bind(v.Interface(), value); err != nil {
+			return nil, fmt.Errorf("invalid value %q of type %s: %w", value, t, err)
+		}
+		set[read(v.UnsafePointer())] = struct{}{}
+	}
+
+	return func(p unsafe.Pointer) bool {
+		_, ok := set[read(p)]
+		return ok
+	}, nil
+}
+
+func minRule(n float64) Rule {
+	return Rule{
+		Name:   "min",
+		Arg:    n,
+		elem:   true,
+		accept: acceptNumber,
+		check: func(t reflect.Type) (func(unsafe.Pointer) error, error) {
+			read := numberReader(t.Kind())
+			return func(p unsafe.Pointer) error {
+				if read(p) < n {
+					return fmt.Errorf("must be greater than or equal to %v", n)
+				}
+				return nil
+			}, nil
+		},
+	}
+}
+
+func maxRule(n float64) Rule {
+	return Rule{
+		Name:   "max",
+		Arg:    n,
+		elem:   true,
+		accept: acceptNumber,
+		check: func(t reflect.Type) (func(unsafe.Pointer) error, error) {
+			read := numberReader(t.Kind())
+			return func(p unsafe.Pointer) error {
+				if read(p) > n {
+					return fmt.Errorf("must be less than or equal to %v", n)
+				}
+				return nil
+			}, nil
+		},
+	}
+}
+
+func minLengthRule(n int) Rule {
+	return Rule{
+		Name:   "minLength",
+		Arg:    n,
+		accept: acceptLength,
+		check: func(t reflect.Type) (func(unsafe.Pointer) error, error) {
+			length := lengthReader(t)
+			return func(p unsafe.Pointer) error {
+				if length(p) < n {
+					return fmt.Errorf("length must be at least %d", n)
+				}
+				return nil
+			}, nil
+		},
+	}
+}
+
+func maxLengthRule(n int) Rule {
+	return Rule{
+		Name:   "maxLength",
+		Arg:    n,
+		accept: acceptLength,
+		check: func(t reflect.Type) (func(unsafe.Pointer) error, error) {
+			length := lengthReader(t)
+			return func(p unsafe.Pointer) error {
+				if length(p) > n {
+					return fmt.Errorf("length must be at most %d", n)
+				}
+				return nil
+			}, nil
+		},
+	}
+}
+
+func patternRule(re *regexp.Regexp) Rule {
+	return Rule{
+		Name:   "pattern",
+		Arg:    re.String(),
+		elem:   true,
+		accept: acceptString,
+		check: func(reflect.Type) (func(unsafe.Pointer) error, error) {
+			return func(p unsafe.Pointer) error {
+				if !re.MatchString(readString(p)) {
+					return fmt.Errorf("must match pattern %q", re.String())
+				}
+				return nil
+			}, nil
+		},
+	}
+}
+
+func enumRule(values []string) Rule {
+	return Rule{
+		Name: "enum",
+		Arg:  values,
+		elem: true,
+		accept: func(t reflect.Type) bool {
+			return acceptString(t) || acceptNumber(t) || t.Kind() == reflect.Bool
+		},
+		check: func(t reflect.Type) (func(unsafe.Pointer) error, error) {
+			var in func(unsafe.Pointer) bool
+			var err error
+			switch k := t.Kind(); {
+			case k == reflect.String:
+				in, err = enumSet(t, values, readString)
+			case k == reflect.Bool:
+				in, err = enumSet(t, values, readBool)
+			case intReader(k) != nil:
+				in, err = enumSet(t, values, intReader(k))
+			case uintReader(k) != nil:
+				in, err = enumSet(t, values, uintReader(k))
+			default:
+				in, err = enumSet(t, values, floatReader(k))
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			return func(p unsafe.Pointer) error {
+				if !in(p) {
+					return fmt.Errorf("must be one of %v", values)
+				}
+				return nil
+			}, nil
+		},
+	}
+}
+
+func requiredRule() Rule {
+	return Rule{
+		Name:   "required",
+		raw:    true,
+		accept: acceptAny,
+		check: func(t reflect.Type) (func(unsafe.Pointer) error, error) {
+			isZero := zeroChecker(t)
+			return func(p unsafe.Pointer) error {
+				if isZero(p) {
+					return fmt.Errorf("is required")
+				}
+				return nil
+			}, nil
+		},
+	}
+}
+
+func customRule(name string, fn func(any) error) Rule {
+	return Rule{
+		Name:   name,
+		accept: acceptAny,
+		check: func(t reflect.Type) (func(unsafe.Pointer) error, error) {
+			value := valueReader(t)
+			return func(p unsafe.Pointer) error {
+				return fn(value(p))
+			}, nil
+		},
+	}
+}
+
+// parseRules parses rules in a `validate` struct tag, like `validate:"required,min=1,max=100"`.
+// Values of `enum` are separated by `|`, like `validate:"enum=asc|desc"`.
+func parseRules(tag string) ([]Rule, error) {
+	var ret []Rule
+	for _, item := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(item, "=")
+
+		var rule Rule
+		var err error
+		switch name {
+		case "":
+			continue
+		case "required":
+			rule = requiredRule()
+		case "min", "max":
+			var n float64
+			n, err = strconv.ParseFloat(arg, 64)
+			if name == "min" {
+				rule = minRule(n)
+			} else {
+				rule = maxRule(n)
+			}
+		case "minlen", "maxlen":
+			var n int
+			n, err = strconv.Atoi(arg)
+			if name == "minlen" {
+				rule = minLengthRule(n)
+			} else {
+				rule = maxLengthRule(n)
+			}
+		case "pattern":
+			var re *regexp.Regexp
+			if re, err = regexp.Compile(arg); err == nil {
+				rule = patternRule(re)
+			}
+		case "enum":
+			rule = enumRule(strings.Split(arg, "|"))
+		default:
+			fn, ok := customRules.Load(name)
+			if !ok {
+				return nil, fmt.Errorf("unknown rule %q", name)
+			}
+			rule = customRule(name, fn.(func(any) error))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %q: %w", item, err)
+		}
+
+		ret = append(ret, rule)
+	}
+
+	return ret, nil
+}
+
+// newParamCheck builds the check of `rules` for a param bound to type `t`. The check gets the pointer of the bound value.
+func newParamCheck(t reflect.Type, rules []Rule) (func(v any) error, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	checks, err := compileRules(t, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	toPointer := rawPointer(t)
+	return func(v any) error {
+		p := toPointer(v)
+		for _, check := range checks {
+			if err := check(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+// FieldError describes the error when a field of a decoded request violates a rule.
+type FieldError struct {
+	Field string
+	Type  reflect.Type
+	Err   error
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("field %q: %v", e.Field, e.Err)
+}
+
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors describes all violations in a decoded request.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	errStr := make([]string, 0, len(e))
+	for _, err := range e {
+		errStr = append(errStr, err.Error())
+	}
+	return strings.Join(errStr, ", ")
+}
+
+func (e ValidationErrors) errorDetails() []ErrorDetail {
+	ret := make([]ErrorDetail, 0, len(e))
+	for _, err := range e {
+		ret = append(ret, ErrorDetail{
+			Field:   err.Field,
+			Source:  BindBodyField.String(),
+			Message: err.Err.Error(),
+		})
+	}
+	return ret
+}
+
+// bindErrors converts violations to errors of binding, to report them with errors of params.
+func (e ValidationErrors) bindErrors() BindErrors {
+	ret := make(BindErrors, 0, len(e))
+	for _, err := range e {
+		ret = append(ret, BindError{
+			Key:  err.Field,
+			From: BindBodyField,
+			Type: err.Type,
+			Err:  err.Err,
+		})
+	}
+	return ret
+}
+
+func (e ValidationErrors) Unwrap() []error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	ret := make([]error, 0, len(e))
+	for _, err := range e {
+		ret = append(ret, err)
+	}
+
+	return ret
+}
+
+type fieldRules struct {
+	name string
+	typ  reflect.Type
+	// field returns the raw pointer to the field in the value at `base`, or nil if the field is in a nil struct.
+	field  func(base unsafe.Pointer) unsafe.Pointer
+	checks []func(p unsafe.Pointer) error
+}
+
+// validator validates a decoded value with rules in `validate` struct tags.
+// Tags are parsed and checks are built once when registering handlers.
+type validator []fieldRules
+
+func newValidator(t reflect.Type) (validator, error) {
+	at := func(base unsafe.Pointer) unsafe.Pointer { return base }
+	for t.Kind() == reflect.Pointer {
+		at = derefAt(at)
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	return appendFieldRules(nil, t, "", at, map[reflect.Type]bool{})
+}
+
+// derefAt returns the function following the pointer at the raw pointer returned by `at`.
+func derefAt(at func(base unsafe.Pointer) unsafe.Pointer) func(base unsafe.Pointer) unsafe.Pointer {
+	return func(base unsafe.Pointer) unsafe.Pointer {
+		if p := at(base); p != nil {
+			return *(*unsafe.Pointer)(p)
+		}
+		return nil
+	}
+}
+
+// appendFieldRules appends rules of fields of the struct type `t`, where `at` returns the raw pointer to the struct.
+func appendFieldRules(ret validator, t reflect.Type, prefix string, at func(base unsafe.Pointer) unsafe.Pointer, visited map[reflect.Type]bool) (validator, error) {
+	if visited[t] {
+		return ret, nil
+	}
+	visited[t] = true
+	defer delete(visited, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name := fieldName(f)
+		if name == "-" {
+			continue
+		}
+
+		offset := f.Offset
+		field := func(base unsafe.Pointer) unsafe.Pointer {
+			if p := at(base); p != nil {
+				return unsafe.Add(p, offset)
+			}
+			return nil
+		}
+
+		if tag, ok := f.Tag.Lookup("validate"); ok {
+			rules, err := parseRules(tag)
+			if err != nil {
+				return nil, fmt.Errorf("validate field %s.%s: %w", t, f.Name, err)
+			}
+
+			for _, rule := range rules {
+				if !rule.accepts(f.Type) {
+					return nil, fmt.Errorf("validate field %s.%s: rule %q doesn't support type %s", t, f.Name, rule.Name, f.Type)
+				}
+			}
+
+			checks, err := compileRules(f.Type, rules)
+			if err != nil {
+				return nil, fmt.Errorf("validate field %s.%s: %w", t, f.Name, err)
+			}
+
+			ret = append(ret, fieldRules{
+				name:   prefix + name,
+				typ:    f.Type,
+				field:  field,
+				checks: checks,
+			})
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			field = derefAt(field)
+			ft = ft.Elem()
+		}
+		if ft.Kind() != reflect.Struct {
+			continue
+		}
+
+		nestPrefix := prefix + name + "."
+		if f.Anonymous {
+			nestPrefix = prefix
+		}
+
+		var err error
+		ret, err = appendFieldRules(ret, ft, nestPrefix, field, visited)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+func fieldName(f reflect.StructField) string {
+	if tag, ok := f.Tag.Lookup("json"); ok {
+		name, _, _ := strings.Cut(tag, ",")
+		if name != "" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+// validate validates the value at the raw pointer `p`, of the type to build the validator.
+func (v validator) validate(p unsafe.Pointer) error {
+	var ret ValidationErrors
+	for _, field := range v {
+		fp := field.field(p)
+		if fp == nil {
+			// Skip fields in nil structs.
+			continue
+		}
+
+		for _, check := range field.checks {
+			if err := check(fp); err != nil {
+				ret = append(ret, FieldError{Field: field.name, Type: field.typ, Err: err})
+			}
+		}
+	}
+
+	if len(ret) == 0 {
+		return nil
+	}
+	return ret
+}
@@ -0,0 +1,231 @@
+package espresso_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/googollee/go-espresso"
+)
+
+func init() {
+	espresso.RegisterRule("even", func(v any) error {
+		if v.(int)%2 != 0 {
+			return errors.New("must be even")
+		}
+		return nil
+	})
+}
+
+func TestValidate(t *testing.T) {
+	type Author struct {
+		Name string `json:"name" validate:"required"`
+	}
+	type Book struct {
+		Title  string   `json:"title" validate:"required,maxlen=10"`
+		Pages  int      `json:"pages" validate:"min=1,even"`
+		Tags   []string `json:"tags" validate:"maxlen=2,enum=go|web"`
+		Author *Author  `json:"author"`
+	}
+
+	espo := espresso.New()
+	espo.AddModule(espresso.ProvideCodecs)
+	espo.HandleFunc(espresso.RPC(func(ctx espresso.Context, book Book) (*Book, error) {
+		var shelf int
+		var sort string
+		if err := ctx.Endpoint(http.MethodPost, "/shelves/{shelf}/books").
+			BindPath("shelf", &shelf, espresso.Min(1), espresso.Max(100)).
+			BindQuery("sort", &sort, espresso.Enum("asc", "desc"), espresso.Pattern("^[a-z]+$")).
+			End(); err != nil {
+			return nil, err
+		}
+
+		return &book, nil
+	}))
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "OK",
+			path:     "/shelves/1/books?sort=asc",
+			body:     `{"title":"espresso","pages":10,"tags":["go"],"author":{"name":"me"}}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"title":"espresso","pages":10,"tags":["go"],"author":{"name":"me"}}` + "\n",
+		},
+		{
+			name:     "InvalidParams",
+			path:     "/shelves/0/books?sort=up",
+			body:     `{"title":"espresso","pages":10}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"message":"bind path with name \"shelf\" to type int error: must be greater than or equal to 1, bind query with name \"sort\" to type string error: must be one of [asc desc]",` +
+				`"errors":[{"field":"shelf","source":"path","message":"must be greater than or equal to 1"},{"field":"sort","source":"query","message":"must be one of [asc desc]"}]}` + "\n",
+		},
+		{
+			name:     "InvalidParamsAndBody",
+			path:     "/shelves/0/books",
+			body:     `{"title":"espresso coffee","pages":10}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"message":"bind path with name \"shelf\" to type int error: must be greater than or equal to 1, bind body with name \"title\" to type string error: length must be at most 10",` +
+				`"errors":[{"field":"shelf","source":"path","message":"must be greater than or equal to 1"},{"field":"title","source":"body","message":"length must be at most 10"}]}` + "\n",
+		},
+		{
+			name:     "InvalidBody",
+			path:     "/shelves/1/books",
+			body:     `{"title":"espresso coffee","pages":3,"tags":["go","rust"],"author":{}}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"message":"bind body with name \"title\" to type string error: length must be at most 10, bind body with name \"pages\" to type int error: must be even, bind body with name \"tags\" to type []string error: element 1: must be one of [go web], bind body with name \"author.name\" to type string error: is required",` +
+				`"errors":[{"field":"title","source":"body","message":"length must be at most 10"},{"field":"pages","source":"body","message":"must be even"},{"field":"tags","source":"body","message":"element 1: must be one of [go web]"},{"field":"author.name","source":"body","message":"is required"}]}` + "\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Post(svr.URL+tc.path, "application/json", strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if got, want := resp.StatusCode, tc.wantCode; got != want {
+				t.Errorf("resp.StatusCode = %d, want: %d", got, want)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := string(body), tc.wantBody; got != want {
+				t.Errorf("resp.Body = %s, want: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestValidateInvalidRule(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("register handler with invalid rule should panic")
+		}
+	}()
+
+	espo := espresso.New()
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		var name string
+		if err := ctx.Endpoint(http.MethodGet, "/").
+			BindQuery("name", &name, espresso.Min(1)).
+			End(); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func TestValidateTypes(t *testing.T) {
+	type Level int
+	type Meta struct {
+		Owner string `json:"owner" validate:"required"`
+	}
+	type Job struct {
+		*Meta
+		Level   Level          `json:"level" validate:"enum=1|2"`
+		Timeout time.Duration  `json:"timeout" validate:"enum=1s|1m"`
+		Ratio   *float32       `json:"ratio" validate:"max=1"`
+		Scores  []*uint        `json:"scores" validate:"minlen=1,max=100"`
+		Labels  map[string]int `json:"labels" validate:"maxlen=1"`
+		Debug   bool           `json:"debug" validate:"enum=false"`
+	}
+
+	espo := espresso.New()
+	espo.AddModule(espresso.ProvideCodecs)
+	espo.HandleFunc(espresso.RPCConsume(func(ctx espresso.Context, job *Job) error {
+		if err := ctx.Endpoint(http.MethodPost, "/jobs").End(); err != nil {
+			return err
+		}
+		return nil
+	}))
+
+	svr := httptest.NewServer(espo)
+	defer svr.Close()
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "OK",
+			body:     `{"level":2,"timeout":60000000000,"scores":[1,null,100]}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Invalid",
+			body:     `{"owner":"","level":3,"timeout":1,"ratio":1.5,"scores":[1,101],"labels":{"a":1,"b":2},"debug":true}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"message":"bind body with name \"owner\" to type string error: is required, bind body with name \"level\" to type espresso_test.Level error: must be one of [1 2], bind body with name \"timeout\" to type time.Duration error: must be one of [1s 1m], bind body with name \"ratio\" to type *float32 error: must be less than or equal to 1, bind body with name \"scores\" to type []*uint error: element 1: must be less than or equal to 100, bind body with name \"labels\" to type map[string]int error: length must be at most 1, bind body with name \"debug\" to type bool error: must be one of [false]",` +
+				`"errors":[{"field":"owner","source":"body","message":"is required"},{"field":"level","source":"body","message":"must be one of [1 2]"},{"field":"timeout","source":"body","message":"must be one of [1s 1m]"},{"field":"ratio","source":"body","message":"must be less than or equal to 1"},{"field":"scores","source":"body","message":"element 1: must be less than or equal to 100"},{"field":"labels","source":"body","message":"length must be at most 1"},{"field":"debug","source":"body","message":"must be one of [false]"}]}` + "\n",
+		},
+		{
+			name:     "Required",
+			body:     `{"level":1,"timeout":1000000000,"scores":[]}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"message":"bind body with name \"scores\" to type []*uint error: length must be at least 1",` +
+				`"errors":[{"field":"scores","source":"body","message":"length must be at least 1"}]}` + "\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Post(svr.URL+"/jobs", "application/json", strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if got, want := resp.StatusCode, tc.wantCode; got != want {
+				t.Errorf("resp.StatusCode = %d, want: %d", got, want)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := string(body), tc.wantBody; got != want {
+				t.Errorf("resp.Body = %s, want: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestValidateInvalidEnum(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("register handler with invalid enum values should panic")
+		}
+	}()
+
+	espo := espresso.New()
+	espo.HandleFunc(func(ctx espresso.Context) error {
+		var limit int
+		if err := ctx.Endpoint(http.MethodGet, "/").
+			BindQuery("limit", &limit, espresso.Enum("10", "ten")).
+			End(); err != nil {
+			return err
+		}
+		return nil
+	})
+}